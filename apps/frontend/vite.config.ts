@@ -4,4 +4,8 @@ import vue from '@vitejs/plugin-vue'
 // https://vite.dev/config/
 export default defineConfig({
   plugins: [vue()],
+  build: {
+    // dist/.vite/manifest.json is used by the E2E asset integrity check
+    manifest: true,
+  },
 })
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Profile roles used by the admin suite.
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+type adminUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// adminCall sends an admin API request as client and returns the status.
+// Any non-2xx status is returned as a BadStatus failure.
+func adminCall(rc *RunContext, client *http.Client, method, path string, v interface{}) (int, error) {
+	url := rc.Config.BackendURL + rc.Config.AdminPath + path
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, requestFailure(method+" "+url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		discardBody(rc, resp)
+		return resp.StatusCode, failf(ReasonBadStatus, "%s %s: status %d", method, url, resp.StatusCode)
+	}
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return resp.StatusCode, requestFailure(method+" "+url, err)
+	}
+	if v != nil {
+		if err := json.Unmarshal(body, v); err != nil {
+			return resp.StatusCode, failf(ReasonSchema, "%s %s: neplatný JSON: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+func forbidden(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusForbidden
+}
+
+// testAdminUsers lists all users as admin and checks that a regular user
+// is refused when the profile defines one.
+func testAdminUsers(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🛡️ TEST: Admin Users")
+
+	var users []adminUser
+	if _, err := adminCall(rc, rc.Roles[RoleAdmin], http.MethodGet, "/users", &users); err != nil {
+		return fmt.Errorf("Seznam uživatelů - %w", err)
+	}
+	fmt.Fprintf(rc.Out, "✅ Admin vidí %d uživatelů\n", len(users))
+
+	if user, ok := rc.Roles[RoleUser]; ok {
+		status, err := adminCall(rc, user, http.MethodGet, "/users", nil)
+		if err == nil {
+			return failf(ReasonAssertion, "Role %s získala přístup k seznamu uživatelů", RoleUser)
+		}
+		if !forbidden(status) {
+			return fmt.Errorf("Seznam uživatelů jako %s - %w", RoleUser, err)
+		}
+		fmt.Fprintf(rc.Out, "✅ Role %s odmítnuta (status %d)\n", RoleUser, status)
+	}
+	return nil
+}
+
+type featuredTask struct {
+	ID       int  `json:"id"`
+	Featured bool `json:"featured"`
+}
+
+func taskFeatured(rc *RunContext, id int) (bool, error) {
+	endpoint, err := rc.endpoint(EndpointMarketplace)
+	if err != nil {
+		return false, err
+	}
+	var tasks []featuredTask
+	if _, err := fetchJSON(rc, endpoint, &tasks); err != nil {
+		return false, err
+	}
+	for _, t := range tasks {
+		if t.ID == id {
+			return t.Featured, nil
+		}
+	}
+	return false, failf(ReasonAssertion, "task %d chybí v marketplace", id)
+}
+
+// testAdminFeatureTask features and unfeatures a scratch marketplace task.
+func testAdminFeatureTask(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n⭐ TEST: Admin Feature Task")
+
+	id, err := createTask(rc, "E2E featured task", "Task pro admin feature test")
+	if err != nil {
+		return fmt.Errorf("Vytvoření tasku selhalo: %w", err)
+	}
+	defer deleteTask(rc, id)
+
+	admin := rc.Roles[RoleAdmin]
+	path := fmt.Sprintf("/tasks/%d/feature", id)
+	for _, step := range []struct {
+		method   string
+		featured bool
+	}{
+		{http.MethodPost, true},
+		{http.MethodDelete, false},
+	} {
+		if _, err := adminCall(rc, admin, step.method, path, nil); err != nil {
+			return fmt.Errorf("Feature tasku - %w", err)
+		}
+		featured, err := taskFeatured(rc, id)
+		if err != nil {
+			return fmt.Errorf("Feature tasku - %w", err)
+		}
+		if featured != step.featured {
+			return failf(ReasonAssertion, "Po %s %s má task featured=%v, očekáváno %v", step.method, path, featured, step.featured)
+		}
+	}
+	fmt.Fprintf(rc.Out, "✅ Task %d zvýrazněn a zvýraznění zrušeno\n", id)
+	return nil
+}
+
+// login posts the credentials of auth without any session and returns the
+// status, like a user opening the login page.
+func login(rc *RunContext, auth AuthConfig) (int, error) {
+	url := rc.Config.BackendURL + auth.LoginPath
+	payload, _ := json.Marshal(map[string]string{"username": auth.Username, "password": auth.Password})
+	client := &http.Client{Timeout: rc.Config.RequestTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return 0, requestFailure("POST "+url, err)
+	}
+	discardBody(rc, resp)
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// testAdminSuspendUser suspends the profile's regular user, checks that
+// their login is rejected and that it works again after unsuspending.
+func testAdminSuspendUser(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🚫 TEST: Admin Suspend User")
+
+	auth := rc.Config.Roles[RoleUser]
+	admin := rc.Roles[RoleAdmin]
+	var users []adminUser
+	if _, err := adminCall(rc, admin, http.MethodGet, "/users", &users); err != nil {
+		return fmt.Errorf("Seznam uživatelů - %w", err)
+	}
+	target := -1
+	for _, u := range users {
+		if strings.EqualFold(u.Username, auth.Username) || strings.EqualFold(u.Email, auth.Username) {
+			target = u.ID
+		}
+	}
+	if target < 0 {
+		return failf(ReasonAssertion, "Uživatel %s role %s v seznamu chybí", auth.Username, RoleUser)
+	}
+
+	path := fmt.Sprintf("/users/%d", target)
+	if _, err := adminCall(rc, admin, http.MethodPost, path+"/suspend", nil); err != nil {
+		return fmt.Errorf("Suspendování - %w", err)
+	}
+	suspended := true
+	defer func() {
+		if suspended {
+			if _, err := adminCall(rc, admin, http.MethodPost, path+"/unsuspend", nil); err != nil {
+				fmt.Fprintf(rc.Out, "⚠️ Obnovení uživatele %d selhalo: %v\n", target, err)
+			}
+		}
+	}()
+
+	status, err := login(rc, auth)
+	if err != nil {
+		return fmt.Errorf("Přihlášení suspendovaného uživatele - %w", err)
+	}
+	if !forbidden(status) {
+		return failf(ReasonAssertion, "Suspendovaný uživatel %s se přihlásil (status %d)", auth.Username, status)
+	}
+	fmt.Fprintf(rc.Out, "✅ Přihlášení suspendovaného uživatele odmítnuto (status %d)\n", status)
+
+	if _, err := adminCall(rc, admin, http.MethodPost, path+"/unsuspend", nil); err != nil {
+		return fmt.Errorf("Obnovení uživatele - %w", err)
+	}
+	suspended = false
+	if status, err = login(rc, auth); err != nil {
+		return fmt.Errorf("Přihlášení obnoveného uživatele - %w", err)
+	}
+	if status != http.StatusOK && status != http.StatusNoContent {
+		return failf(ReasonAssertion, "Obnovený uživatel %s se nepřihlásil (status %d)", auth.Username, status)
+	}
+	fmt.Fprintf(rc.Out, "✅ Po obnovení se uživatel opět přihlásí\n")
+	return nil
+}
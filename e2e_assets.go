@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// manifestChunk is one entry of a Vite build manifest. Integrity is set by
+// SRI plugins (sha256-/sha384-/sha512- base64 digests).
+type manifestChunk struct {
+	File      string   `json:"file"`
+	CSS       []string `json:"css"`
+	Assets    []string `json:"assets"`
+	Integrity string   `json:"integrity"`
+}
+
+// assetDigests hashes one asset with every SRI algorithm in a single pass.
+type assetDigests struct {
+	sha256 []byte
+	sha384 []byte
+	sha512 []byte
+}
+
+func (d assetDigests) matches(integrity string) bool {
+	for _, token := range strings.Fields(integrity) {
+		algo, digest, ok := strings.Cut(token, "-")
+		if !ok {
+			continue
+		}
+		var sum []byte
+		switch algo {
+		case "sha256":
+			sum = d.sha256
+		case "sha384":
+			sum = d.sha384
+		case "sha512":
+			sum = d.sha512
+		}
+		if sum != nil && base64.StdEncoding.EncodeToString(sum) == digest {
+			return true
+		}
+	}
+	return false
+}
+
+func digestStream(r io.Reader, limit int64) (assetDigests, int64, error) {
+	h256, h384, h512 := sha256.New(), sha512.New384(), sha512.New()
+	n, err := io.Copy(io.MultiWriter(h256, h384, h512), io.LimitReader(r, limit+1))
+	if err != nil {
+		return assetDigests{}, n, err
+	}
+	if n > limit {
+		return assetDigests{}, n, fmt.Errorf("%w %d B", errBodyTooLarge, limit)
+	}
+	sum := func(h hash.Hash) []byte { return h.Sum(nil) }
+	return assetDigests{sum(h256), sum(h384), sum(h512)}, n, nil
+}
+
+// loadManifest reads the manifest from the frontend, or from the local
+// build output when only -frontend-dist is given.
+func loadManifest(rc *RunContext) (map[string]manifestChunk, error) {
+	var data []byte
+	if rc.Config.AssetManifest != "" {
+		status, body, err := fetchFrontend(rc, rc.Config.AssetManifest)
+		if err != nil {
+			return nil, err
+		}
+		if status != http.StatusOK {
+			return nil, failf(ReasonBadStatus, "GET %s: status %d", rc.Config.AssetManifest, status)
+		}
+		data = body
+	} else {
+		body, err := os.ReadFile(filepath.Join(rc.Config.FrontendDist, ".vite", "manifest.json"))
+		if err != nil {
+			return nil, err
+		}
+		data = body
+	}
+
+	var manifest map[string]manifestChunk
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, failf(ReasonSchema, "manifest není platný JSON: %v", err)
+	}
+	return manifest, nil
+}
+
+// manifestAssets returns every deployed file referenced by the manifest with
+// the integrity recorded for it, if any.
+func manifestAssets(manifest map[string]manifestChunk) map[string]string {
+	assets := map[string]string{}
+	add := func(file, integrity string) {
+		if file == "" {
+			return
+		}
+		if assets[file] == "" {
+			assets[file] = integrity
+		}
+	}
+	for _, chunk := range manifest {
+		add(chunk.File, chunk.Integrity)
+		for _, f := range chunk.CSS {
+			add(f, "")
+		}
+		for _, f := range chunk.Assets {
+			add(f, "")
+		}
+	}
+	return assets
+}
+
+// checkAsset fetches one deployed asset and compares it with the manifest
+// integrity, or byte for byte with the local build when -frontend-dist is set.
+// verified is false when there was nothing to compare against.
+func checkAsset(rc *RunContext, file, integrity string) (verified bool, err error) {
+	resp, err := rc.Client.Get(strings.TrimSuffix(rc.Config.FrontendURL, "/") + "/" + file)
+	if err != nil {
+		return false, requestFailure(file, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, failf(ReasonBadStatus, "%s: status %d", file, resp.StatusCode)
+	}
+	deployed, size, err := digestStream(resp.Body, rc.Config.MaxBodyBytes)
+	if err != nil {
+		return false, requestFailure(file, err)
+	}
+
+	if integrity != "" {
+		if !deployed.matches(integrity) {
+			return true, failf(ReasonAssertion, "%s: obsah (%d B) neodpovídá integrity %s", file, size, integrity)
+		}
+		return true, nil
+	}
+	if rc.Config.FrontendDist == "" {
+		return false, nil
+	}
+
+	local, err := os.Open(filepath.Join(rc.Config.FrontendDist, file))
+	if err != nil {
+		return false, fmt.Errorf("%s: lokální build: %w", file, err)
+	}
+	defer local.Close()
+	built, _, err := digestStream(local, rc.Config.MaxBodyBytes)
+	if err != nil {
+		return false, fmt.Errorf("%s: lokální build: %w", file, err)
+	}
+	if !bytes.Equal(built.sha256, deployed.sha256) {
+		return true, failf(ReasonAssertion, "%s: nasazený soubor (%d B) se liší od buildu", file, size)
+	}
+	return true, nil
+}
+
+// testAssetIntegrity detects CDN cache corruption and partial deploys by
+// verifying every asset of the build manifest.
+func testAssetIntegrity(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🧬 TEST: Static Asset Integrity")
+
+	manifest, err := loadManifest(rc)
+	if err != nil {
+		return fmt.Errorf("Build manifest nelze načíst: %w", err)
+	}
+	assets := manifestAssets(manifest)
+	files := make([]string, 0, len(assets))
+	for file := range assets {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var errs []error
+	verified := 0
+	for _, file := range files {
+		ok, err := checkAsset(rc, file, assets[file])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			verified++
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if verified == 0 {
+		return failf(ReasonAssertion, "Manifest neobsahuje integrity a není nastaven -frontend-dist, %d assetů nelze ověřit", len(files))
+	}
+	fmt.Fprintf(rc.Out, "✅ Ověřeno %d/%d assetů z manifestu\n", verified, len(files))
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+type auditEntry struct {
+	ID         int    `json:"id"`
+	EntityType string `json:"entity_type"`
+	EntityID   int    `json:"entity_id"`
+	Action     string `json:"action"`
+	Actor      string `json:"actor"`
+	Timestamp  string `json:"timestamp"`
+}
+
+// auditTimeLayouts covers RFC 3339 and the naive ISO format of Python's
+// datetime.isoformat() and SQLite.
+var auditTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05"}
+
+func parseAuditTime(value string) (time.Time, error) {
+	for _, layout := range auditTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("neznámý formát času %q", value)
+}
+
+func updateTask(rc *RunContext, id int, fields map[string]string) error {
+	payload, _ := json.Marshal(fields)
+	url := fmt.Sprintf("%s/api/tasks/%d", rc.Config.BackendURL, id)
+	req, _ := http.NewRequest(http.MethodPut, url, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := rc.Client.Do(req)
+	if err != nil {
+		return requestFailure("PUT "+url, err)
+	}
+	discardBody(rc, resp)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return failf(ReasonBadStatus, "PUT %s: status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// testAuditLog creates, updates and deletes a task and checks that the audit
+// log, read as admin when the profile has that role, records each action
+// with actor, timestamp and entity reference in that order.
+func testAuditLog(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n📜 TEST: Audit Log")
+
+	id, err := createTask(rc, "E2E audit task", "Task pro audit log test")
+	if err != nil {
+		return fmt.Errorf("Vytvoření tasku selhalo: %w", err)
+	}
+	deleted := false
+	defer func() {
+		if !deleted {
+			deleteTask(rc, id)
+		}
+	}()
+	if err := updateTask(rc, id, map[string]string{"title": "E2E audit task (upraveno)"}); err != nil {
+		return fmt.Errorf("Úprava tasku selhala: %w", err)
+	}
+	deleteTask(rc, id)
+	deleted = true
+	expected := []string{"create", "update", "delete"}
+
+	arc := *rc
+	if admin, ok := rc.Roles[RoleAdmin]; ok {
+		arc.Client = admin
+	}
+	var entries []auditEntry
+	url := fmt.Sprintf("%s/api/audit?entity_type=task&entity_id=%d", rc.Config.BackendURL, id)
+	if _, err := fetchJSON(&arc, url, &entries); err != nil {
+		return fmt.Errorf("Audit log - %w", err)
+	}
+
+	times := map[int]time.Time{}
+	for _, e := range entries {
+		t, err := parseAuditTime(e.Timestamp)
+		if err != nil {
+			return failf(ReasonSchema, "Audit záznam %d: %v", e.ID, err)
+		}
+		times[e.ID] = t
+	}
+	// The API lists newest first; IDs break ties within one timestamp.
+	sort.SliceStable(entries, func(i, j int) bool {
+		ti, tj := times[entries[i].ID], times[entries[j].ID]
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+
+	var actions []string
+	for _, e := range entries {
+		if e.EntityType != "task" || e.EntityID != id {
+			return failf(ReasonAssertion, "Audit záznam %d odkazuje na %s %d místo task %d", e.ID, e.EntityType, e.EntityID, id)
+		}
+		if e.Actor == "" {
+			return failf(ReasonSchema, "Audit záznam %d (%s) nemá actor", e.ID, e.Action)
+		}
+		actions = append(actions, e.Action)
+	}
+	if fmt.Sprint(actions) != fmt.Sprint(expected) {
+		return failf(ReasonAssertion, "Audit log tasku %d obsahuje %v, očekáváno %v", id, actions, expected)
+	}
+	fmt.Fprintf(rc.Out, "✅ Audit log zaznamenal %v s actorem a časem\n", actions)
+	return nil
+}
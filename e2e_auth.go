@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// AuthProvider authenticates requests sent to the backend. Implementations
+// are chosen per environment profile, so the same suites run against an
+// unauthenticated dev server and a gateway-protected staging.
+type AuthProvider interface {
+	Name() string
+	Apply(req *http.Request) error
+}
+
+// AuthConfig is the "auth" section of an environment profile. Secrets are
+// read from the environment variables named by the *_env fields.
+type AuthConfig struct {
+	Type        string `json:"type"`
+	Header      string `json:"header"`
+	KeyEnv      string `json:"key_env"`
+	TokenEnv    string `json:"token_env"`
+	Username    string `json:"username"`
+	PasswordEnv string `json:"password_env"`
+	LoginPath   string `json:"login_path"`
+
+	// Resolved secrets, filled in by loadProfile.
+	Key      string `json:"-"`
+	Token    string `json:"-"`
+	Password string `json:"-"`
+}
+
+type noAuth struct{}
+
+func (noAuth) Name() string                  { return "none" }
+func (noAuth) Apply(req *http.Request) error { return nil }
+
+type apiKeyAuth struct {
+	header string
+	key    string
+}
+
+func (a apiKeyAuth) Name() string { return "api-key" }
+
+func (a apiKeyAuth) Apply(req *http.Request) error {
+	req.Header.Set(a.header, a.key)
+	return nil
+}
+
+type basicAuth struct {
+	username string
+	password string
+}
+
+func (a basicAuth) Name() string { return "basic" }
+
+func (a basicAuth) Apply(req *http.Request) error {
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+type bearerAuth struct {
+	token string
+}
+
+func (a bearerAuth) Name() string { return "bearer" }
+
+func (a bearerAuth) Apply(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+// sessionAuth logs in once with username and password and sends the session
+// cookies with every later request.
+type sessionAuth struct {
+	client   *http.Client
+	loginURL string
+	username string
+	password string
+
+	mu      sync.Mutex
+	cookies []*http.Cookie
+}
+
+func (a *sessionAuth) Name() string { return "session" }
+
+func (a *sessionAuth) Apply(req *http.Request) error {
+	cookies, err := a.session()
+	if err != nil {
+		return err
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	return nil
+}
+
+func (a *sessionAuth) session() ([]*http.Cookie, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cookies != nil {
+		return a.cookies, nil
+	}
+
+	payload, _ := json.Marshal(map[string]string{"username": a.username, "password": a.password})
+	resp, err := a.client.Post(a.loginURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("přihlášení selhalo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return nil, fmt.Errorf("přihlášení vrátilo status %d", resp.StatusCode)
+	}
+	if len(resp.Cookies()) == 0 {
+		return nil, fmt.Errorf("přihlášení nevrátilo session cookie")
+	}
+	a.cookies = resp.Cookies()
+	return a.cookies, nil
+}
+
+// newAuthProvider builds the provider described by auth, which has been
+// validated by loadProfile. client is used for session logins only.
+func newAuthProvider(cfg Config, auth AuthConfig, client *http.Client) AuthProvider {
+	switch auth.Type {
+	case "api-key":
+		header := auth.Header
+		if header == "" {
+			header = "X-API-Key"
+		}
+		return apiKeyAuth{header: header, key: auth.Key}
+	case "basic":
+		return basicAuth{username: auth.Username, password: auth.Password}
+	case "bearer":
+		return bearerAuth{token: auth.Token}
+	case "session":
+		return &sessionAuth{
+			client:   client,
+			loginURL: cfg.BackendURL + auth.LoginPath,
+			username: auth.Username,
+			password: auth.Password,
+		}
+	}
+	return noAuth{}
+}
+
+// authTransport applies the auth provider to requests for the backend and its
+// services only, so credentials never leak to the frontend, toxiproxy or
+// other hosts.
+type authTransport struct {
+	next     http.RoundTripper
+	auth     AuthProvider
+	backends []string
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !matchesBase(req.URL.String(), t.backends) {
+		return t.next.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	if err := t.auth.Apply(req); err != nil {
+		return nil, fmt.Errorf("auth %s: %w", t.auth.Name(), err)
+	}
+	return t.next.RoundTrip(req)
+}
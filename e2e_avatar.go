@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// avatarSource is one generated upload. Sizes cover a large photo, a non
+// square image and one smaller than the usual variants (must not upscale).
+type avatarSource struct {
+	format        string
+	width, height int
+}
+
+var avatarSources = []avatarSource{
+	{"png", 1024, 1024},
+	{"jpeg", 1280, 720},
+	{"png", 48, 64},
+}
+
+// avatarVariant is one resized image returned by the upload endpoint.
+// Width and height are optional.
+type avatarVariant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type avatarResponse struct {
+	Variants []avatarVariant `json:"variants"`
+}
+
+func encodeAvatar(src avatarSource) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, src.width, src.height))
+	for y := 0; y < src.height; y++ {
+		for x := 0; x < src.width; x++ {
+			img.Set(x, y, color.RGBA{uint8(255 * x / src.width), uint8(255 * y / src.height), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	var err error
+	if src.format == "jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	return buf.Bytes(), err
+}
+
+func uploadAvatar(rc *RunContext, src avatarSource, data []byte) (avatarResponse, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", fmt.Sprintf("avatar-%dx%d.%s", src.width, src.height, src.format))
+	if err != nil {
+		return avatarResponse{}, err
+	}
+	part.Write(data)
+	form.Close()
+
+	target := rc.Config.BackendURL + rc.Config.AvatarPath
+	resp, err := rc.Client.Post(target, form.FormDataContentType(), &body)
+	if err != nil {
+		return avatarResponse{}, requestFailure("POST "+target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return avatarResponse{}, failf(ReasonBadStatus, "POST %s: status %d", target, resp.StatusCode)
+	}
+	raw, err := readBody(rc, resp)
+	if err != nil {
+		return avatarResponse{}, requestFailure("POST "+target, err)
+	}
+	var result avatarResponse
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return avatarResponse{}, failf(ReasonSchema, "POST %s: neplatný JSON: %v", target, err)
+	}
+	if len(result.Variants) == 0 {
+		return avatarResponse{}, failf(ReasonSchema, "POST %s: odpověď neobsahuje variants", target)
+	}
+	return result, nil
+}
+
+// checkVariant fetches one variant and decodes its header to compare the
+// format with Content-Type and the dimensions with the declared ones.
+func checkVariant(rc *RunContext, src avatarSource, v avatarVariant) error {
+	base, _ := url.Parse(rc.Config.BackendURL)
+	ref, err := url.Parse(v.URL)
+	if err != nil || v.URL == "" {
+		return failf(ReasonSchema, "Varianta %s má neplatnou URL %q", v.Name, v.URL)
+	}
+	target := base.ResolveReference(ref).String()
+
+	resp, err := rc.Client.Get(target)
+	if err != nil {
+		return requestFailure("GET "+target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return failf(ReasonBadStatus, "GET %s: status %d", target, resp.StatusCode)
+	}
+	data, err := readBody(rc, resp)
+	if err != nil {
+		return requestFailure("GET "+target, err)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return failf(ReasonSchema, "Varianta %s nelze dekódovat: %v", v.Name, err)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "image/"+format {
+		return failf(ReasonSchema, "Varianta %s: Content-Type %q, obsah je %s", v.Name, mediaType, format)
+	}
+	if v.Width > 0 && v.Height > 0 && (config.Width != v.Width || config.Height != v.Height) {
+		return failf(ReasonAssertion, "Varianta %s: rozměry %dx%d, API uvádí %dx%d", v.Name, config.Width, config.Height, v.Width, v.Height)
+	}
+	if config.Width > src.width || config.Height > src.height {
+		return failf(ReasonAssertion, "Varianta %s: %dx%d je větší než originál %dx%d", v.Name, config.Width, config.Height, src.width, src.height)
+	}
+	fmt.Fprintf(rc.Out, "   %s: %dx%d %s\n", v.Name, config.Width, config.Height, mediaType)
+	return nil
+}
+
+// testAvatarUpload uploads generated PNG and JPEG avatars and verifies every
+// resized variant the API returns.
+func testAvatarUpload(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🖼️ TEST: Avatar Upload")
+
+	var errs []error
+	for _, src := range avatarSources {
+		data, err := encodeAvatar(src)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(rc.Out, "   Upload %s %dx%d (%d B)\n", src.format, src.width, src.height, len(data))
+		result, err := uploadAvatar(rc, src, data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Upload %s %dx%d - %w", src.format, src.width, src.height, err))
+			continue
+		}
+		for _, v := range result.Variants {
+			if err := checkVariant(rc, src, v); err != nil {
+				errs = append(errs, fmt.Errorf("Avatar %s %dx%d - %w", src.format, src.width, src.height, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	fmt.Fprintf(rc.Out, "✅ Všechny varianty avatarů mají správný formát a rozměry\n")
+	return nil
+}
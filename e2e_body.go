@@ -0,0 +1,50 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var errBodyTooLarge = errors.New("tělo odpovědi přesahuje limit")
+
+// readLimited reads r up to limit bytes. A longer stream is cut off and
+// reported as an error instead of being buffered in full.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return data, err
+	}
+	if int64(len(data)) > limit {
+		return data[:limit], fmt.Errorf("%w %d B", errBodyTooLarge, limit)
+	}
+	return data, nil
+}
+
+// readBody reads a response body capped at -max-body.
+func readBody(rc *RunContext, resp *http.Response) ([]byte, error) {
+	return readLimited(resp.Body, rc.Config.MaxBodyBytes)
+}
+
+// hashBody streams a response body through SHA-256 without buffering it and
+// returns the hex digest and the number of bytes read.
+func hashBody(rc *RunContext, resp *http.Response) (string, int64, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, io.LimitReader(resp.Body, rc.Config.MaxBodyBytes+1))
+	if err != nil {
+		return "", n, err
+	}
+	if n > rc.Config.MaxBodyBytes {
+		return "", n, fmt.Errorf("%w %d B", errBodyTooLarge, rc.Config.MaxBodyBytes)
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// discardBody drains at most -max-body bytes so the connection can be
+// reused; anything longer is left for Close to drop.
+func discardBody(rc *RunContext, resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, rc.Config.MaxBodyBytes))
+}
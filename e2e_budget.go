@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// suiteName is the key of the current suite in budget and timing files, so
+// each CI stage (post-deploy smoke, full functional gate) has its own budget.
+func suiteName(cfg Config) string {
+	if cfg.Smoke {
+		return "smoke"
+	}
+	return "functional"
+}
+
+// loadBudgets reads a flat YAML map of suite to duration, e.g.
+//
+//	smoke: 60s
+//	functional: 5m
+func loadBudgets(path string) (map[string]time.Duration, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	budgets := map[string]time.Duration{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(text, ":")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: očekáváno suite: doba", path, line)
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		budget, err := time.ParseDuration(value)
+		if err != nil || budget <= 0 {
+			return nil, fmt.Errorf("%s:%d: neplatná doba %q", path, line, value)
+		}
+		budgets[strings.TrimSpace(key)] = budget
+	}
+	return budgets, scanner.Err()
+}
+
+// checkBudget returns a failed outcome when the suite took longer than its
+// budget. It is critical, so it fails the run regardless of -fail-on.
+func checkBudget(cfg Config, elapsed time.Duration) (TestOutcome, bool) {
+	suite := suiteName(cfg)
+	budget, ok := cfg.Budgets[suite]
+	if !ok || elapsed <= budget {
+		return TestOutcome{}, false
+	}
+	return TestOutcome{
+		Name:     "Budget: " + suite,
+		Suite:    SuiteHarness,
+		Severity: SeverityCritical,
+		Reason:   ReasonTimeout,
+		Message:  fmt.Sprintf("Suite %s trvala %s, rozpočet je %s", suite, elapsed.Round(time.Millisecond), budget),
+		Attempts: 1,
+		Duration: elapsed,
+	}, true
+}
+
+type suiteTiming struct {
+	DurationS  float64  `json:"duration_s"`
+	BudgetS    *float64 `json:"budget_s,omitempty"`
+	OverBudget bool     `json:"over_budget"`
+}
+
+type timingFile struct {
+	Generated string                 `json:"generated"`
+	Suites    map[string]suiteTiming `json:"suites"`
+	Tests     map[string]float64     `json:"tests_s"`
+}
+
+// saveTimingFile writes suite and per-test durations in seconds. CI stages
+// can merge the suites maps of several runs into one budget overview.
+func saveTimingFile(cfg Config, results TestResult, elapsed time.Duration) {
+	timing := suiteTiming{DurationS: elapsed.Seconds()}
+	if budget, ok := cfg.Budgets[suiteName(cfg)]; ok {
+		seconds := budget.Seconds()
+		timing.BudgetS = &seconds
+		timing.OverBudget = elapsed > budget
+	}
+	file := timingFile{
+		Generated: timestamp(cfg, time.Now()),
+		Suites:    map[string]suiteTiming{suiteName(cfg): timing},
+		Tests:     map[string]float64{},
+	}
+	for _, o := range results.Outcomes {
+		if !strings.HasPrefix(o.Name, "Budget: ") {
+			file.Tests[o.Name] = o.Duration.Seconds()
+		}
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err == nil {
+		err = os.WriteFile(cfg.TimingFile, append(data, '\n'), 0644)
+	}
+	if err != nil {
+		reportHarnessError(TelemetryReporter, err)
+		fmt.Printf("⚠️ Časový soubor nelze uložit: %v\n", err)
+		return
+	}
+	fmt.Printf("📄 Časy suite uloženy do: %s\n", cfg.TimingFile)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ChaosAction is a fault injected between two tests. Inject breaks a
+// dependency, Restore puts it back; the harness then waits for the backend
+// to report healthy again.
+type ChaosAction struct {
+	Name    string
+	Inject  func(rc *RunContext) error
+	Restore func(rc *RunContext) error
+}
+
+func chaosActions(names []string) ([]ChaosAction, error) {
+	var actions []ChaosAction
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case "restart-backend":
+			actions = append(actions, ChaosAction{
+				Name: "restart-backend",
+				Inject: func(rc *RunContext) error {
+					return dockerCommand("stop", rc.Config.BackendContainer)
+				},
+				Restore: func(rc *RunContext) error {
+					return dockerCommand("start", rc.Config.BackendContainer)
+				},
+			})
+		case "startup-order":
+			// The backend starts while its database is down and has to
+			// become healthy on its own once the database comes up.
+			actions = append(actions, ChaosAction{
+				Name: "startup-order",
+				Inject: func(rc *RunContext) error {
+					if rc.Config.ComposeFile == "" {
+						return fmt.Errorf("startup-order vyžaduje -compose-file")
+					}
+					if err := composeCommand(rc, "stop", rc.Config.ComposeBackend, rc.Config.ComposeDB); err != nil {
+						return err
+					}
+					return composeCommand(rc, "up", "-d", "--no-deps", rc.Config.ComposeBackend)
+				},
+				Restore: func(rc *RunContext) error {
+					return composeCommand(rc, "up", "-d", "--no-deps", rc.Config.ComposeDB)
+				},
+			})
+		case "drop-db":
+			actions = append(actions, ChaosAction{
+				Name: "drop-db",
+				Inject: func(rc *RunContext) error {
+					if rc.Toxiproxy == nil {
+						return fmt.Errorf("drop-db vyžaduje -toxiproxy")
+					}
+					return rc.Toxiproxy.SetEnabled(rc.Config.ToxiproxyProxy, false)
+				},
+				Restore: func(rc *RunContext) error {
+					return rc.Toxiproxy.SetEnabled(rc.Config.ToxiproxyProxy, true)
+				},
+			})
+		case "":
+		default:
+			return nil, fmt.Errorf("neznámá chaos akce: %s", name)
+		}
+	}
+	return actions, nil
+}
+
+// chaosSchedule spreads actions evenly over the test list and returns them
+// keyed by the index of the test after which they run.
+func chaosSchedule(testCount int, actions []ChaosAction) map[int][]ChaosAction {
+	schedule := map[int][]ChaosAction{}
+	for k, action := range actions {
+		after := (k+1)*testCount/(len(actions)+1) - 1
+		if after < 0 {
+			after = 0
+		}
+		schedule[after] = append(schedule[after], action)
+	}
+	return schedule
+}
+
+func runChaosAction(rc *RunContext, action ChaosAction) error {
+	fmt.Fprintf(rc.Out, "\n💥 CHAOS: %s\n", action.Name)
+
+	if err := action.Inject(rc); err != nil {
+		// A half-applied injection may have stopped services already.
+		injectErr := failf(ReasonConnection, "Chaos %s - injekce selhala: %v", action.Name, err)
+		if err := action.Restore(rc); err != nil {
+			return errors.Join(injectErr, failf(ReasonConnection, "Chaos %s - obnova selhala: %v", action.Name, err))
+		}
+		return injectErr
+	}
+
+	degraded := false
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) {
+		if state := probeHealth(rc); state != "ok" {
+			fmt.Fprintf(rc.Out, "✅ Degradace zaznamenána: %s\n", state)
+			degraded = true
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	if err := action.Restore(rc); err != nil {
+		return failf(ReasonConnection, "Chaos %s - obnova selhala: %v", action.Name, err)
+	}
+
+	start := time.Now()
+	deadline = start.Add(rc.Config.RecoveryTimeout)
+	for time.Now().Before(deadline) {
+		if probeHealth(rc) == "ok" {
+			fmt.Fprintf(rc.Out, "✅ Backend zotaven za %s\n", time.Since(start).Round(time.Millisecond))
+			if !degraded {
+				return failf(ReasonAssertion, "Chaos %s - backend degradaci nehlásil", action.Name)
+			}
+			return nil
+		}
+		time.Sleep(time.Second)
+	}
+
+	return failf(ReasonTimeout, "Chaos %s - backend se nezotavil do %s", action.Name, rc.Config.RecoveryTimeout)
+}
+
+// probeHealth returns the reported health status, or "down: <reason>" when
+// the endpoint cannot be read at all.
+func probeHealth(rc *RunContext) string {
+	resp, err := rc.Client.Get(rc.Config.BackendURL + "/health")
+	if err != nil {
+		return fmt.Sprintf("down: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return fmt.Sprintf("down: %v", err)
+	}
+	var health HealthResponse
+	if err := json.Unmarshal(body, &health); err != nil {
+		return fmt.Sprintf("down: HTTP %d", resp.StatusCode)
+	}
+	return health.Status
+}
+
+func composeCommand(rc *RunContext, args ...string) error {
+	return dockerCommand(append([]string{"compose", "-f", rc.Config.ComposeFile}, args...)...)
+}
+
+func dockerCommand(args ...string) error {
+	out, err := exec.Command("docker", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("docker %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// clockSample is the offset of a server clock from the local one. The Date
+// header has second resolution, so the offset is only known within margin.
+type clockSample struct {
+	host   string
+	offset time.Duration
+	margin time.Duration
+}
+
+// excess is how far the offset surely exceeds zero, after the margin.
+func (s clockSample) excess() time.Duration {
+	offset := s.offset
+	if offset < 0 {
+		offset = -offset
+	}
+	return max(offset-s.margin, 0)
+}
+
+func sampleClock(rc *RunContext, url string) (clockSample, error) {
+	sent := time.Now()
+	resp, err := rc.Client.Get(url)
+	if err != nil {
+		return clockSample{}, err
+	}
+	received := time.Now()
+	discardBody(rc, resp)
+	resp.Body.Close()
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return clockSample{}, fmt.Errorf("chybí Date header")
+	}
+	rtt := received.Sub(sent)
+	local := sent.Add(rtt / 2)
+	// The server time lies somewhere within the second Date names.
+	return clockSample{host: url, offset: date.Add(500 * time.Millisecond).Sub(local), margin: 500*time.Millisecond + rtt/2}, nil
+}
+
+// checkClockSkew compares the local clock with the Date headers of the
+// backend and frontend at run start. Time-window tests fail confusingly on a
+// drifting CI runner, so skew above -clock-skew-warn is reported as a
+// warning and above -clock-skew-fail as a failure.
+func checkClockSkew(rc *RunContext) (TestOutcome, bool) {
+	var worst *clockSample
+	for _, url := range []string{rc.Config.BackendURL + "/health", rc.Config.FrontendURL} {
+		s, err := sampleClock(rc, url)
+		if err != nil {
+			fmt.Printf("⚠️ Čas serveru %s nelze zjistit: %v\n", url, err)
+			continue
+		}
+		fmt.Printf("🕰️ Posun hodin %s: %s (±%s)\n", url, s.offset.Round(time.Millisecond), s.margin.Round(time.Millisecond))
+		if worst == nil || s.excess() > worst.excess() {
+			worst = &s
+		}
+	}
+	if worst == nil {
+		return TestOutcome{}, false
+	}
+
+	o := TestOutcome{Name: "Clock Skew", Suite: SuiteHarness, Severity: SeverityMajor, Attempts: 1}
+	skew := worst.offset.Round(time.Millisecond)
+	switch excess := worst.excess(); {
+	case rc.Config.ClockSkewFail > 0 && excess > rc.Config.ClockSkewFail:
+		o.Reason = ReasonAssertion
+		o.Message = fmt.Sprintf("Hodiny testeru se liší od %s o %s (limit %s)", worst.host, skew, rc.Config.ClockSkewFail)
+	case rc.Config.ClockSkewWarn > 0 && excess > rc.Config.ClockSkewWarn:
+		o.Passed, o.Warning = true, true
+		o.Message = fmt.Sprintf("Hodiny testeru se liší od %s o %s (varování nad %s)", worst.host, skew, rc.Config.ClockSkewWarn)
+	default:
+		return TestOutcome{}, false
+	}
+	return o, true
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// minCompressedAsset is the size from which a JS or CSS asset must be served
+// compressed; servers commonly skip smaller files.
+const minCompressedAsset = 1024
+
+var assetRefPattern = regexp.MustCompile(`(?:src|href)="(/?[^"?#:]+\.(?:js|css))"`)
+
+// encodedResponse is one fetch of a resource with its body decoded.
+type encodedResponse struct {
+	encoding string
+	vary     string
+	body     []byte
+}
+
+// fetchEncoded GETs target with the given Accept-Encoding. Setting the header
+// turns off the transparent gzip of net/http, so the raw encoding is seen
+// and decoded here.
+func fetchEncoded(rc *RunContext, target, acceptEncoding string) (encodedResponse, error) {
+	req, _ := http.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	resp, err := rc.Client.Do(req)
+	if err != nil {
+		return encodedResponse{}, requestFailure("GET "+target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		discardBody(rc, resp)
+		return encodedResponse{}, failf(ReasonBadStatus, "GET %s: status %d", target, resp.StatusCode)
+	}
+
+	r := encodedResponse{
+		encoding: strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))),
+		vary:     strings.Join(resp.Header.Values("Vary"), ","),
+	}
+	if r.encoding == "identity" {
+		r.encoding = ""
+	}
+	raw, err := readBody(rc, resp)
+	if err != nil {
+		return r, requestFailure("GET "+target, err)
+	}
+	if r.body, err = decodeBody(rc, r.encoding, raw); err != nil {
+		return r, failf(ReasonAssertion, "GET %s: tělo neodpovídá Content-Encoding %s: %v", target, r.encoding, err)
+	}
+	return r, nil
+}
+
+func decodeBody(rc *RunContext, encoding string, raw []byte) ([]byte, error) {
+	var r io.Reader
+	switch encoding {
+	case "":
+		return raw, nil
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		r = zr
+	case "deflate":
+		// "deflate" means zlib, but some servers send a raw deflate stream.
+		zr, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			r = flate.NewReader(bytes.NewReader(raw))
+		} else {
+			r = zr
+		}
+	default:
+		return nil, fmt.Errorf("nepodporované kódování")
+	}
+	// A small compressed body may expand a lot; keep the -max-body cap.
+	return readLimited(r, rc.Config.MaxBodyBytes)
+}
+
+func varies(vary, header string) bool {
+	for _, v := range strings.Split(vary, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.EqualFold(v, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEncoding fetches target once as identity and once gzip-encoded and
+// compares the two. With mustCompress the gzip fetch of a large enough
+// resource has to come back compressed.
+func checkEncoding(rc *RunContext, label, target string, mustCompress bool) error {
+	plain, err := fetchEncoded(rc, target, "identity")
+	if err != nil {
+		return err
+	}
+	if plain.encoding != "" {
+		return failf(ReasonAssertion, "%s: Content-Encoding %s, přestože klient přijímá jen identity", label, plain.encoding)
+	}
+	encoded, err := fetchEncoded(rc, target, "gzip")
+	if err != nil {
+		return err
+	}
+
+	switch encoded.encoding {
+	case "":
+		if mustCompress && len(plain.body) >= minCompressedAsset {
+			return failf(ReasonAssertion, "%s: %d B se posílá nekomprimovaně i s Accept-Encoding: gzip", label, len(plain.body))
+		}
+		fmt.Fprintf(rc.Out, "   %s: bez komprese (%d B)\n", label, len(plain.body))
+	case "gzip", "x-gzip":
+		if !varies(encoded.vary, "Accept-Encoding") {
+			return failf(ReasonAssertion, "%s: komprimovaná odpověď bez Vary: Accept-Encoding, cache ji může vrátit klientům bez gzip", label)
+		}
+		fmt.Fprintf(rc.Out, "   %s: gzip, Vary: %s (%d B)\n", label, encoded.vary, len(encoded.body))
+	default:
+		return failf(ReasonAssertion, "%s: Content-Encoding %s, klient přijímá jen gzip", label, encoded.encoding)
+	}
+
+	if !bytes.Equal(plain.body, encoded.body) {
+		return failf(ReasonAssertion, "%s: dekódované tělo (%d B) se liší od nekomprimovaného (%d B)", label, len(encoded.body), len(plain.body))
+	}
+	return nil
+}
+
+// frontendAssets lists the JS and CSS files the landing page references,
+// plus the build manifest when one is configured.
+func frontendAssets(rc *RunContext, index []byte) []string {
+	seen := map[string]bool{}
+	for _, m := range assetRefPattern.FindAllSubmatch(index, -1) {
+		seen[strings.TrimPrefix(string(m[1]), "/")] = true
+	}
+	if rc.Config.AssetManifest != "" || rc.Config.FrontendDist != "" {
+		if manifest, err := loadManifest(rc); err == nil {
+			for file := range manifestAssets(manifest) {
+				if strings.HasSuffix(file, ".js") || strings.HasSuffix(file, ".css") {
+					seen[file] = true
+				}
+			}
+		}
+	}
+	assets := make([]string, 0, len(seen))
+	for file := range seen {
+		assets = append(assets, file)
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+// testCompression checks that compressed and uncompressed responses carry the
+// same content, that compression is declared correctly, and that the
+// pre-compressed frontend assets are actually served compressed.
+func testCompression(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🗜️ TEST: Response Compression")
+
+	// A large description pushes the task over typical compression thresholds.
+	id, err := createTask(rc, "E2E compression "+newRunID(), strings.Repeat("Komprimovatelný popis úkolu. ", 200))
+	if err != nil {
+		return fmt.Errorf("Vytvoření úkolu: %w", err)
+	}
+	defer deleteTask(rc, id)
+
+	var errs []error
+	if err := checkEncoding(rc, fmt.Sprintf("GET /api/tasks/%d", id), fmt.Sprintf("%s/api/tasks/%d", rc.Config.BackendURL, id), false); err != nil {
+		errs = append(errs, err)
+	}
+
+	frontend := strings.TrimSuffix(rc.Config.FrontendURL, "/")
+	if err := checkEncoding(rc, "GET /", frontend+"/", false); err != nil {
+		errs = append(errs, err)
+	}
+	_, index, err := fetchFrontend(rc, "/")
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	assets := frontendAssets(rc, index)
+	for _, file := range assets {
+		if err := checkEncoding(rc, "/"+file, frontend+"/"+file, true); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	fmt.Fprintf(rc.Out, "✅ Komprese v pořádku (API, index a %d assetů)\n", len(assets))
+	return nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// testCost is the wall and CPU time one test added to the run. CPU is the
+// process CPU delta over the test, so it is approximate and left out under
+// -parallel, where concurrent tests share the delta.
+type testCost struct {
+	Name     string   `json:"name"`
+	Group    string   `json:"group"`
+	Smoke    bool     `json:"smoke"`
+	Severity Severity `json:"severity"`
+	Status   string   `json:"status"`
+	WallS    float64  `json:"wall_s"`
+	CPUS     float64  `json:"cpu_s,omitempty"`
+}
+
+type groupCost struct {
+	Group string  `json:"group"`
+	Tests int     `json:"tests"`
+	WallS float64 `json:"wall_s"`
+	CPUS  float64 `json:"cpu_s,omitempty"`
+	Share float64 `json:"share"`
+}
+
+// costReport attributes the run time to groups and proposes how to move
+// tests between the smoke gate and the nightly run to fit GateTarget.
+type costReport struct {
+	Generated string      `json:"generated"`
+	RunID     string      `json:"run_id"`
+	WallS     float64     `json:"wall_s"`
+	CPUS      float64     `json:"cpu_s"`
+	Parallel  int         `json:"parallel"`
+	Groups    []groupCost `json:"groups"`
+	Tests     []testCost  `json:"tests"`
+	Gate      struct {
+		TargetS   float64 `json:"target_s"`
+		EstimateS float64 `json:"estimate_s"`
+		// ToSmoke are nightly tests that fit into the gate's headroom,
+		// ToNightly smoke tests to drop until the gate fits its target.
+		ToSmoke   []string `json:"to_smoke"`
+		ToNightly []string `json:"to_nightly"`
+	} `json:"gate"`
+}
+
+func seconds(d time.Duration) float64 {
+	return float64(d.Milliseconds()) / 1000
+}
+
+// buildCostReport needs the test list for the smoke flags, which the
+// outcomes do not carry. A -smoke run only sees the gate's own tests.
+func buildCostReport(cfg Config, tests []testCase, results TestResult) costReport {
+	smoke := map[string]bool{}
+	for _, t := range tests {
+		smoke[t.name] = t.smoke
+	}
+	parallel := max(cfg.Parallel, 1)
+	report := costReport{Generated: timestamp(cfg, time.Now()), RunID: results.RunID, Parallel: parallel, Tests: []testCost{}}
+	groups := map[string]*groupCost{}
+	for _, o := range results.Outcomes {
+		c := testCost{o.Name, o.Suite, smoke[o.Name], o.Severity, reportTest(o).Status, seconds(o.Duration), 0}
+		if parallel == 1 {
+			c.CPUS = seconds(o.CPU)
+		}
+		report.Tests = append(report.Tests, c)
+		report.WallS += c.WallS
+		report.CPUS += c.CPUS
+		g, ok := groups[c.Group]
+		if !ok {
+			g = &groupCost{Group: c.Group}
+			groups[c.Group] = g
+		}
+		g.Tests++
+		g.WallS += c.WallS
+		g.CPUS += c.CPUS
+	}
+	if parallel > 1 {
+		// Only the process total is meaningful when tests overlap.
+		report.CPUS = seconds(processCPU())
+	}
+	for _, g := range groups {
+		if report.WallS > 0 {
+			g.Share = g.WallS / report.WallS
+		}
+		report.Groups = append(report.Groups, *g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool { return report.Groups[i].WallS > report.Groups[j].WallS })
+	sort.SliceStable(report.Tests, func(i, j int) bool { return report.Tests[i].WallS > report.Tests[j].WallS })
+
+	report.Gate.TargetS = seconds(cfg.GateTarget)
+	report.Gate.ToSmoke, report.Gate.ToNightly = []string{}, []string{}
+	var serial float64
+	for _, c := range report.Tests {
+		if c.Smoke {
+			serial += c.WallS
+		}
+	}
+	// Tests of one batch share the workers, so the gate takes roughly the
+	// serial time divided by -parallel.
+	report.Gate.EstimateS = serial / float64(parallel)
+	planGate(&report, report.Gate.EstimateS)
+	return report
+}
+
+// planGate fills the gate candidates. Over target, the least severe and
+// slowest smoke tests go nightly first; critical tests always stay. Under
+// target, the most severe and cheapest passing nightly tests fill the
+// headroom.
+func planGate(report *costReport, estimate float64) {
+	target := report.Gate.TargetS
+	perTest := func(c testCost) float64 { return c.WallS / float64(report.Parallel) }
+	var smoke, nightly []testCost
+	for _, c := range report.Tests {
+		switch {
+		case c.Group == SuiteHarness:
+		case c.Smoke:
+			smoke = append(smoke, c)
+		case c.Status == "passed" && c.Severity != SeverityMinor:
+			nightly = append(nightly, c)
+		}
+	}
+
+	if estimate > target {
+		sort.SliceStable(smoke, func(i, j int) bool {
+			if smoke[i].Severity != smoke[j].Severity {
+				return smoke[i].Severity.rank() < smoke[j].Severity.rank()
+			}
+			return smoke[i].WallS > smoke[j].WallS
+		})
+		for _, c := range smoke {
+			if estimate <= target || c.Severity == SeverityCritical {
+				break
+			}
+			report.Gate.ToNightly = append(report.Gate.ToNightly, c.Name)
+			estimate -= perTest(c)
+		}
+		return
+	}
+	sort.SliceStable(nightly, func(i, j int) bool {
+		if nightly[i].Severity != nightly[j].Severity {
+			return nightly[i].Severity.rank() > nightly[j].Severity.rank()
+		}
+		return nightly[i].WallS < nightly[j].WallS
+	})
+	for _, c := range nightly {
+		if estimate+perTest(c) > target {
+			continue
+		}
+		report.Gate.ToSmoke = append(report.Gate.ToSmoke, c.Name)
+		estimate += perTest(c)
+	}
+}
+
+// renderCost formats the attribution for the console.
+func renderCost(report costReport) string {
+	out := "\n💰 NÁKLADY BĚHU:\n"
+	if report.Parallel > 1 {
+		out += fmt.Sprintf("  Celkem: %.1fs wall, ~%.1fs CPU celého harnessu (-parallel %d, CPU po testech nelze rozlišit)\n", report.WallS, report.CPUS, report.Parallel)
+	} else {
+		out += fmt.Sprintf("  Celkem: %.1fs wall, ~%.1fs CPU harnessu (součet testů, přibližně)\n", report.WallS, report.CPUS)
+	}
+	for _, g := range report.Groups {
+		line := fmt.Sprintf("  %-14s %3d testů  %7.1fs  %3.0f%%", g.Group, g.Tests, g.WallS, 100*g.Share)
+		if report.Parallel == 1 {
+			line += fmt.Sprintf("  CPU ~%.1fs", g.CPUS)
+		}
+		out += line + "\n"
+	}
+	out += "  Nejdražší testy:\n"
+	for _, c := range report.Tests[:min(5, len(report.Tests))] {
+		tier := "nightly"
+		if c.Smoke {
+			tier = "smoke"
+		}
+		out += fmt.Sprintf("    %-40s %7.1fs  %-7s %s\n", c.Name, c.WallS, tier, c.Severity)
+	}
+	gate := report.Gate
+	icon := "✅"
+	if gate.EstimateS > gate.TargetS {
+		icon = "❌"
+	}
+	out += fmt.Sprintf("  %s Deploy gate: odhad %.2fs, cíl %.2fs\n", icon, gate.EstimateS, gate.TargetS)
+	if len(gate.ToNightly) > 0 {
+		out += fmt.Sprintf("  ➡️ Přesunout do nightly: %s\n", strings.Join(gate.ToNightly, ", "))
+	}
+	if len(gate.ToSmoke) > 0 {
+		out += fmt.Sprintf("  ⬅️ Vejde se do smoke: %s\n", strings.Join(gate.ToSmoke, ", "))
+	}
+	return out
+}
+
+func saveCostReport(cfg Config, tests []testCase, results TestResult) {
+	report := buildCostReport(cfg, tests, results)
+	fmt.Print(renderCost(report))
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		err = os.WriteFile(cfg.CostReportPath, append(data, '\n'), 0644)
+	}
+	if err != nil {
+		reportHarnessError(TelemetryReporter, err)
+		fmt.Printf("⚠️ Report nákladů nelze uložit: %v\n", err)
+		return
+	}
+	fmt.Printf("📄 Report nákladů uložen do: %s\n", cfg.CostReportPath)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// fetchFrontendFile GETs a file from the frontend host. SPA hosting rewrites
+// unknown paths to index.html, so an HTML answer means the file is missing.
+func fetchFrontendFile(rc *RunContext, path string) ([]byte, error) {
+	target := strings.TrimSuffix(rc.Config.FrontendURL, "/") + path
+	resp, err := rc.Client.Get(target)
+	if err != nil {
+		return nil, requestFailure("GET "+target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, failf(ReasonBadStatus, "GET %s: status %d", path, resp.StatusCode)
+	}
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return nil, requestFailure("GET "+target, err)
+	}
+	head := strings.ToLower(string(bytes.TrimSpace(body[:min(len(body), 512)])))
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") || strings.HasPrefix(head, "<!doctype html") || strings.HasPrefix(head, "<html") {
+		return nil, failf(ReasonAssertion, "%s chybí, frontend vrací index.html", path)
+	}
+	if len(body) == 0 {
+		return nil, failf(ReasonAssertion, "%s je prázdný", path)
+	}
+	return body, nil
+}
+
+func absoluteURL(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// checkRobots requires a User-agent group and absolute Sitemap references.
+func checkRobots(rc *RunContext) error {
+	body, err := fetchFrontendFile(rc, "/robots.txt")
+	if err != nil {
+		return err
+	}
+	agents, blocked, agent := 0, false, ""
+	for _, line := range strings.Split(string(body), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "user-agent":
+			agents++
+			agent = value
+		case "disallow":
+			blocked = blocked || agent == "*" && value == "/"
+		case "sitemap":
+			if !absoluteURL(value) {
+				return failf(ReasonAssertion, "/robots.txt: Sitemap %q není absolutní URL", value)
+			}
+		}
+	}
+	if agents == 0 {
+		return failf(ReasonAssertion, "/robots.txt neobsahuje žádné User-agent pravidlo")
+	}
+	fmt.Fprintf(rc.Out, "✅ /robots.txt: %d User-agent pravidel\n", agents)
+	if blocked {
+		fmt.Fprintln(rc.Out, "   ⚠️ Disallow: / pro všechny crawlery (v produkci zablokuje indexaci)")
+	}
+	return nil
+}
+
+// checkSitemap requires a urlset or sitemap index with absolute locations.
+func checkSitemap(rc *RunContext) error {
+	body, err := fetchFrontendFile(rc, "/sitemap.xml")
+	if err != nil {
+		return err
+	}
+	var sitemap struct {
+		XMLName xml.Name
+		URLs    []string `xml:"url>loc"`
+		Indexes []string `xml:"sitemap>loc"`
+	}
+	if err := xml.Unmarshal(body, &sitemap); err != nil {
+		return failf(ReasonSchema, "/sitemap.xml není validní XML: %v", err)
+	}
+	if root := sitemap.XMLName.Local; root != "urlset" && root != "sitemapindex" {
+		return failf(ReasonSchema, "/sitemap.xml má kořen <%s> místo <urlset> nebo <sitemapindex>", root)
+	}
+	locs := append(sitemap.URLs, sitemap.Indexes...)
+	if len(locs) == 0 {
+		return failf(ReasonAssertion, "/sitemap.xml neobsahuje žádné <loc>")
+	}
+	for _, loc := range locs {
+		if !absoluteURL(strings.TrimSpace(loc)) {
+			return failf(ReasonAssertion, "/sitemap.xml: %q není absolutní URL", loc)
+		}
+	}
+	fmt.Fprintf(rc.Out, "✅ /sitemap.xml: <%s> s %d URL\n", sitemap.XMLName.Local, len(locs))
+	return nil
+}
+
+// checkFavicon requires an image, sniffed from the content itself.
+func checkFavicon(rc *RunContext) error {
+	body, err := fetchFrontendFile(rc, "/favicon.ico")
+	if err != nil {
+		return err
+	}
+	kind := http.DetectContentType(body)
+	if !strings.HasPrefix(kind, "image/") {
+		return failf(ReasonAssertion, "/favicon.ico není obrázek (%s)", kind)
+	}
+	fmt.Fprintf(rc.Out, "✅ /favicon.ico: %s, %d B\n", kind, len(body))
+	return nil
+}
+
+// testCrawlerFiles checks robots.txt, sitemap.xml and favicon.ico, which
+// tend to disappear from the frontend build after deploys.
+func testCrawlerFiles(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🤖 TEST: Crawler Files")
+
+	var errs []error
+	for _, check := range []func(*RunContext) error{checkRobots, checkSitemap, checkFavicon} {
+		if err := check(rc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// csvImportJob is the import job status. Errors reference the malformed
+// rows either by data row or by file line.
+type csvImportJob struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Total   int    `json:"total"`
+	Created int    `json:"created"`
+	Failed  int    `json:"failed"`
+	Errors  []struct {
+		Row   int    `json:"row"`
+		Error string `json:"error"`
+	} `json:"errors"`
+	TaskIDs []int `json:"task_ids"`
+}
+
+// importCSV builds the file: valid rows carry marker in the title, malformed
+// rows lack a title or have an unknown priority. It returns the 1-based data
+// rows that must be rejected.
+func importCSV(marker string) ([]byte, []int, int) {
+	rows := [][]string{
+		{marker + " 1", "První importovaný task", "high"},
+		{"", "Řádek bez názvu", "low"},
+		{marker + " 2", "Druhý importovaný task", "medium"},
+		{marker + " 3", "Čárka, \"uvozovky\" a diakritika", "low"},
+		{marker + " 4", "Neznámá priorita", "urgent!!"},
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"title", "description", "priority"})
+	w.WriteAll(rows)
+	return buf.Bytes(), []int{2, 5}, 3
+}
+
+func startCSVImport(rc *RunContext, data []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "tasks.csv")
+	part.Write(data)
+	form.Close()
+
+	target := rc.Config.BackendURL + rc.Config.CSVImportPath
+	resp, err := rc.Client.Post(target, form.FormDataContentType(), &body)
+	if err != nil {
+		return "", requestFailure("POST "+target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
+		return "", failf(ReasonBadStatus, "POST %s: status %d", target, resp.StatusCode)
+	}
+	raw, err := readBody(rc, resp)
+	if err != nil {
+		return "", requestFailure("POST "+target, err)
+	}
+	return jobLocation(rc, "POST "+target, resp, raw, rc.Config.CSVImportPath)
+}
+
+// importedTasks returns the IDs of tasks whose title carries marker.
+func importedTasks(rc *RunContext, marker string) ([]int, error) {
+	var tasks []struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+	if _, err := fetchJSON(rc, rc.Config.BackendURL+"/api/tasks", &tasks); err != nil {
+		return nil, err
+	}
+	var ids []int
+	for _, t := range tasks {
+		if strings.HasPrefix(t.Title, marker) {
+			ids = append(ids, t.ID)
+		}
+	}
+	return ids, nil
+}
+
+// testCSVImport uploads a CSV with valid and malformed rows, follows the
+// import job and checks that exactly the valid rows became tasks and the
+// malformed ones are reported.
+func testCSVImport(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n📥 TEST: CSV Task Import")
+
+	marker := "E2E import " + newRunID()
+	data, malformed, valid := importCSV(marker)
+	statusURL, err := startCSVImport(rc, data)
+	if err != nil {
+		return fmt.Errorf("CSV import - %w", err)
+	}
+	defer func() {
+		ids, _ := importedTasks(rc, marker)
+		for _, id := range ids {
+			deleteTask(rc, id)
+		}
+	}()
+
+	var job csvImportJob
+	if _, err := followJob(rc, statusURL, &job); err != nil {
+		return fmt.Errorf("CSV import - %w", err)
+	}
+	fmt.Fprintf(rc.Out, "   Job %s: %s, vytvořeno %d, chybných %d\n", job.ID, job.Status, job.Created, job.Failed)
+
+	var problems []string
+	if job.Created != valid {
+		problems = append(problems, fmt.Sprintf("vytvořeno %d místo %d", job.Created, valid))
+	}
+	if job.Failed != len(malformed) {
+		problems = append(problems, fmt.Sprintf("chybných %d místo %d", job.Failed, len(malformed)))
+	}
+	var rows []int
+	for _, e := range job.Errors {
+		rows = append(rows, e.Row)
+	}
+	sort.Ints(rows)
+	// File lines are data rows shifted by the header.
+	lines := make([]int, len(malformed))
+	for i, row := range malformed {
+		lines[i] = row + 1
+	}
+	if fmt.Sprint(rows) != fmt.Sprint(malformed) && fmt.Sprint(rows) != fmt.Sprint(lines) {
+		problems = append(problems, fmt.Sprintf("chyby hlášeny u řádků %v, očekáváno %v", rows, malformed))
+	}
+
+	ids, err := importedTasks(rc, marker)
+	if err != nil {
+		return fmt.Errorf("Seznam tasků - %w", err)
+	}
+	if len(ids) != job.Created {
+		problems = append(problems, fmt.Sprintf("job hlásí %d vytvořených, v API je %d", job.Created, len(ids)))
+	}
+	if len(problems) > 0 {
+		return failf(ReasonAssertion, "CSV import: %s", strings.Join(problems, "; "))
+	}
+	fmt.Fprintf(rc.Out, "✅ Importováno %d tasků, %d chybných řádků nahlášeno\n", len(ids), len(malformed))
+	return nil
+}
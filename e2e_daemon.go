@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const signatureHeader = "X-Able2flow-Signature"
+
+// runRequest is the webhook payload, e.g. sent by the deploy pipeline.
+type runRequest struct {
+	Env         string `json:"env"`
+	CallbackURL string `json:"callback_url"`
+	DeployID    string `json:"deploy_id"`
+}
+
+type daemonJob struct {
+	runID string
+	req   runRequest
+}
+
+// runCallback is posted to the callback URL once the run is done.
+type runCallback struct {
+	RunID    string          `json:"run_id"`
+	DeployID string          `json:"deploy_id,omitempty"`
+	Env      string          `json:"env"`
+	Passed   bool            `json:"passed"`
+	Error    string          `json:"error,omitempty"`
+	Report   json.RawMessage `json:"report,omitempty"`
+}
+
+// sign returns the hex HMAC-SHA256 of body in the "sha256=<hex>" form used
+// for both incoming webhooks and outgoing callbacks.
+func sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func validSignature(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(sign(secret, body)), []byte(signature))
+}
+
+type daemon struct {
+	secret  []byte
+	runArgs []string
+	jobs    chan daemonJob
+}
+
+// daemonCommand serves authenticated webhooks that trigger a smoke run
+// against an environment profile, and optionally runs smoke checks on a
+// fixed interval. Flags after "--" are passed to every run, e.g.
+// `daemon -listen :8090 -- -config profiles.json`.
+func daemonCommand(args []string) int {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	listen := fs.String("listen", ":8090", "adresa HTTP serveru pro webhooky")
+	secretEnv := fs.String("secret-env", "ABLE2FLOW_WEBHOOK_SECRET", "proměnná prostředí s HMAC secretem webhooků")
+	interval := fs.Duration("interval", 0, "interval pravidelných smoke běhů (0 = jen webhooky)")
+	intervalEnv := fs.String("interval-env", "dev", "prostředí pravidelných smoke běhů")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	secret := os.Getenv(*secretEnv)
+	if secret == "" {
+		fmt.Printf("❌ Proměnná %s s secretem webhooků není nastavena\n", *secretEnv)
+		return 2
+	}
+	d := &daemon{secret: []byte(secret), runArgs: fs.Args(), jobs: make(chan daemonJob, 8)}
+	startup := runRequest{}
+	if *interval > 0 {
+		startup.Env = *intervalEnv
+	}
+	if _, err := d.config(startup); err != nil {
+		reportHarnessError(TelemetryConfig, err)
+		fmt.Printf("❌ Argumenty běhu: %v\n", err)
+		return 2
+	}
+
+	go d.worker()
+	if *interval > 0 {
+		go d.schedule(*interval, *intervalEnv)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hooks/run", d.handleRun)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	fmt.Printf("🛰️ Daemon naslouchá na %s (POST /hooks/run)\n", *listen)
+	if err := http.ListenAndServe(*listen, mux); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (d *daemon) config(req runRequest) (Config, error) {
+	args := append(append([]string{}, d.runArgs...), "-smoke")
+	if req.Env != "" {
+		args = append(args, "-env", req.Env)
+	}
+	if req.DeployID != "" {
+		args = append(args, "-deploy-id", req.DeployID)
+	}
+	return parseConfig("daemon", args)
+}
+
+func (d *daemon) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := readLimited(r.Body, 64<<10)
+	if err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	if !validSignature(d.secret, body, r.Header.Get(signatureHeader)) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var req runRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.CallbackURL != "" && !strings.HasPrefix(req.CallbackURL, "http://") && !strings.HasPrefix(req.CallbackURL, "https://") {
+		http.Error(w, "callback_url must be http(s)", http.StatusBadRequest)
+		return
+	}
+	if _, err := d.config(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	job := daemonJob{runID: newRunID(), req: req}
+	select {
+	case d.jobs <- job:
+	default:
+		http.Error(w, "run queue full", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Printf("📨 Webhook: smoke běh %s pro prostředí %s (deploy %s)\n", job.runID, req.Env, req.DeployID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"run_id": job.runID})
+}
+
+func (d *daemon) schedule(interval time.Duration, env string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		select {
+		case d.jobs <- daemonJob{runID: newRunID(), req: runRequest{Env: env}}:
+		default:
+			fmt.Println("⚠️ Pravidelný smoke běh přeskočen, fronta je plná")
+		}
+	}
+}
+
+// worker executes queued runs one at a time, so webhook bursts never run
+// suites concurrently against the same environment.
+func (d *daemon) worker() {
+	for job := range d.jobs {
+		cb := runCallback{RunID: job.runID, DeployID: job.req.DeployID, Env: job.req.Env}
+		cfg, err := d.config(job.req)
+		var results TestResult
+		if err == nil {
+			cfg.RunID = job.runID
+			results, err = executeRun(cfg)
+		}
+		if err != nil {
+			cb.Error = err.Error()
+		} else {
+			cb.Passed = len(blockingFailures(results, cfg.FailOn)) == 0
+			cb.Report, _ = renderJSONReport(cfg, results)
+		}
+		fmt.Printf("🏁 Běh %s dokončen: passed=%v\n", job.runID, cb.Passed)
+
+		if job.req.CallbackURL != "" {
+			if err := d.callback(job.req.CallbackURL, cb); err != nil {
+				fmt.Printf("⚠️ Callback %s selhal: %v\n", job.req.CallbackURL, err)
+			}
+		}
+	}
+}
+
+// callback posts the result signed with the webhook secret, so the receiver
+// can verify it came from this daemon.
+func (d *daemon) callback(url string, cb runCallback) error {
+	body, err := json.Marshal(cb)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(signatureHeader, sign(d.secret, body))
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
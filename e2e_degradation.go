@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// dependencyToggle switches a non-critical dependency off and on, either
+// through the admin API or by stopping its compose service.
+type dependencyToggle struct {
+	name    string
+	service string
+}
+
+// parseDependencyToggle parses -dependency-toggle: "email" uses the admin
+// hook, "email=mailpit" stops the compose service mailpit.
+func parseDependencyToggle(value string) dependencyToggle {
+	name, service, _ := strings.Cut(value, "=")
+	return dependencyToggle{name: name, service: service}
+}
+
+func (d dependencyToggle) set(rc *RunContext, enabled bool) error {
+	if d.service != "" {
+		if rc.Config.ComposeFile == "" {
+			return fmt.Errorf("%s=%s vyžaduje -compose-file", d.name, d.service)
+		}
+		if enabled {
+			return composeCommand(rc, "start", d.service)
+		}
+		return composeCommand(rc, "stop", d.service)
+	}
+	client := rc.Client
+	if admin, ok := rc.Roles[RoleAdmin]; ok {
+		client = admin
+	}
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+	_, err := adminCall(rc, client, http.MethodPost, "/dependencies/"+d.name+"/"+action, nil)
+	return err
+}
+
+func fetchHealth(rc *RunContext) (HealthResponse, error) {
+	var health HealthResponse
+	resp, err := rc.Client.Get(rc.Config.BackendURL + "/health")
+	if err != nil {
+		return health, requestFailure("GET /health", err)
+	}
+	defer resp.Body.Close()
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return health, requestFailure("GET /health", err)
+	}
+	if err := json.Unmarshal(body, &health); err != nil {
+		return health, failf(ReasonSchema, "GET /health: status %d, neplatný JSON: %v", resp.StatusCode, err)
+	}
+	return health, nil
+}
+
+// degradedComponent returns the unhealthy component named after the
+// dependency, e.g. "email" or "email_service" for email.
+func degradedComponent(health HealthResponse, dependency string) (string, bool) {
+	for name, c := range health.Components {
+		if !c.healthy() && strings.Contains(strings.ToLower(name), strings.ToLower(dependency)) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// coreFlows creates, reads, lists and deletes a task, the path users need
+// even with non-critical dependencies down.
+func coreFlows(rc *RunContext) error {
+	id, err := createTask(rc, "E2E degradace "+newRunID(), "Task vytvořený při vypnuté závislosti")
+	if err != nil {
+		return fmt.Errorf("Vytvoření tasku: %w", err)
+	}
+	defer deleteTask(rc, id)
+
+	var task map[string]interface{}
+	if _, err := fetchJSON(rc, fmt.Sprintf("%s/api/tasks/%d", rc.Config.BackendURL, id), &task); err != nil {
+		return fmt.Errorf("Načtení tasku %d: %w", id, err)
+	}
+	endpoint, err := rc.endpoint(EndpointMarketplace)
+	if err != nil {
+		return err
+	}
+	var list []json.RawMessage
+	if _, err := fetchJSON(rc, endpoint, &list); err != nil {
+		return fmt.Errorf("Marketplace: %w", err)
+	}
+	fmt.Fprintf(rc.Out, "   Task %d vytvořen, načten a marketplace vrací %d tasků\n", id, len(list))
+	return nil
+}
+
+// testDependencyDegradation switches off -dependency-toggle and checks that
+// /health names it as degraded while the core flows keep working, then that
+// the backend is healthy again once it is back.
+func testDependencyDegradation(rc *RunContext) error {
+	dep := parseDependencyToggle(rc.Config.DependencyToggle)
+	fmt.Fprintf(rc.Out, "\n🪫 TEST: Dependency Degradation (%s)\n", dep.name)
+
+	if err := dep.set(rc, false); err != nil {
+		return fmt.Errorf("Vypnutí závislosti %s: %w", dep.name, err)
+	}
+	restored := false
+	defer func() {
+		if !restored {
+			dep.set(rc, true)
+		}
+	}()
+
+	var health HealthResponse
+	var component string
+	var err error
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		health, err = fetchHealth(rc)
+		if err == nil && health.Status == "down" {
+			return failf(ReasonAssertion, "Po vypnutí %s hlásí backend down: %s", dep.name, strings.Join(health.unhealthy(), ", "))
+		}
+		found := false
+		if err == nil {
+			component, found = degradedComponent(health, dep.name)
+		}
+		if found && health.Status == "degraded" || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	if err != nil {
+		return fmt.Errorf("Health po vypnutí %s: %w", dep.name, err)
+	}
+
+	var errs []error
+	switch {
+	case component == "":
+		errs = append(errs, failf(ReasonAssertion, "Health nehlásí komponentu %s jako nefunkční (status %s, komponenty: %s)", dep.name, health.Status, strings.Join(health.unhealthy(), ", ")))
+	case health.Status != "degraded":
+		errs = append(errs, failf(ReasonAssertion, "Health hlásí %s jako nefunkční, ale status je %q místo degraded", component, health.Status))
+	default:
+		fmt.Fprintf(rc.Out, "✅ Health hlásí degraded: %s\n", strings.Join(health.unhealthy(), ", "))
+	}
+	if err := coreFlows(rc); err != nil {
+		errs = append(errs, fmt.Errorf("Hlavní flow při vypnuté závislosti %s: %w", dep.name, err))
+	}
+
+	restored = true
+	if err := dep.set(rc, true); err != nil {
+		return errors.Join(append(errs, fmt.Errorf("Zapnutí závislosti %s: %w", dep.name, err))...)
+	}
+	start := time.Now()
+	for probeHealth(rc) != "ok" {
+		if time.Since(start) > rc.Config.RecoveryTimeout {
+			errs = append(errs, failf(ReasonTimeout, "Backend po zapnutí %s nehlásí ok do %s", dep.name, rc.Config.RecoveryTimeout))
+			return errors.Join(errs...)
+		}
+		time.Sleep(time.Second)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	fmt.Fprintf(rc.Out, "✅ Hlavní flow fungují bez %s, backend zotaven za %s\n", dep.name, time.Since(start).Round(time.Millisecond))
+	return nil
+}
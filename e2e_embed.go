@@ -0,0 +1,84 @@
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// The example configuration ships inside the binary, so a runner with just
+// the able2flow executable can bootstrap its profiles and budgets.
+var (
+	//go:embed e2e_profiles.example.json
+	exampleProfiles []byte
+	//go:embed e2e_budgets.example.yaml
+	exampleBudgets []byte
+)
+
+// exampleFiles maps the names written by init to the embedded content.
+var exampleFiles = []struct {
+	name string
+	data []byte
+}{
+	{"profiles.json", exampleProfiles},
+	{"budgets.yaml", exampleBudgets},
+}
+
+// writeExamples writes the embedded examples into dir. Existing files are
+// kept unless force is set.
+func writeExamples(dir string, force bool) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, f := range exampleFiles {
+		path := filepath.Join(dir, f.name)
+		flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+		if force {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		}
+		file, err := os.OpenFile(path, flags, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			return written, fmt.Errorf("%s už existuje (přepsání: -force)", path)
+		}
+		if err != nil {
+			return written, err
+		}
+		_, err = file.Write(f.data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// initCommand writes the embedded example profiles and budgets, so the
+// standalone binary needs no checkout of the repository.
+func initCommand(args []string) int {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	dir := fs.String("dir", ".", "adresář, kam se zapíší ukázkové profiles.json a budgets.yaml")
+	force := fs.Bool("force", false, "přepíše existující soubory")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	written, err := writeExamples(*dir, *force)
+	for _, path := range written {
+		fmt.Printf("📄 Zapsán %s\n", path)
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	fmt.Printf("✅ Upravte URL a proměnné s tajemstvími, pak spusťte: able2flow run -config %s -env dev -budget %s\n",
+		filepath.Join(*dir, "profiles.json"), filepath.Join(*dir, "budgets.yaml"))
+	return 0
+}
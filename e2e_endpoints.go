@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Logical endpoints whose location differs between backend versions.
+const (
+	EndpointMarketplace   = "marketplace"
+	EndpointLeaderboard   = "leaderboard"
+	EndpointNotifications = "notifications"
+)
+
+// endpointCandidates lists the known locations of each logical endpoint in
+// order of preference, relative to the base URL of the serving service.
+var endpointCandidates = []struct {
+	name    string
+	service string
+	paths   []string
+}{
+	{EndpointMarketplace, ServiceTasks, []string{"/api/tasks", "/tasks", "/api/marketplace", "/marketplace"}},
+	{EndpointLeaderboard, ServiceUsers, []string{"/api/leaderboard", "/leaderboard", "/api/users/leaderboard", "/api/users"}},
+	{EndpointNotifications, ServiceNotifications, []string{"/api/notifications", "/notifications"}},
+}
+
+type endpointResolution struct {
+	URL string
+	Err error
+}
+
+// Endpoints holds the canonical URL of every logical endpoint, resolved once
+// before the suite runs. It is read-only afterwards, so tests share it
+// through their copies of the run context.
+type Endpoints map[string]endpointResolution
+
+// resolveEndpoints probes the candidates of every logical endpoint and keeps
+// the first one answering 200 with a JSON list.
+func resolveEndpoints(rc *RunContext) Endpoints {
+	fmt.Println("\n🧭 Rozpoznávám endpointy backendu...")
+	endpoints := Endpoints{}
+	for _, c := range endpointCandidates {
+		var res endpointResolution
+		base := rc.Config.serviceURL(c.service)
+		for _, path := range c.paths {
+			var list []json.RawMessage
+			if _, err := fetchJSON(rc, base+path, &list); err != nil {
+				res.Err = err
+				continue
+			}
+			res = endpointResolution{URL: base + path}
+			break
+		}
+		endpoints[c.name] = res
+
+		if res.Err != nil {
+			fmt.Printf("   ⚠️ %s: nenalezen (%v)\n", c.name, res.Err)
+		} else {
+			fmt.Printf("   %s → %s\n", c.name, res.URL)
+		}
+	}
+	return endpoints
+}
+
+// endpoint returns the resolved URL of a logical endpoint.
+func (rc *RunContext) endpoint(name string) (string, error) {
+	res, ok := rc.Endpoints[name]
+	if !ok {
+		return "", failf(ReasonAssertion, "endpoint %s nebyl rozpoznán", name)
+	}
+	if res.Err != nil {
+		return "", fmt.Errorf("endpoint %s nenalezen: %w", name, res.Err)
+	}
+	return res.URL, nil
+}
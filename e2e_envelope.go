@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// envelopeProbes are requests every backend version must reject with a 4xx.
+var envelopeProbes = []struct {
+	method string
+	path   string
+	body   string
+}{
+	{http.MethodGet, "/api/tasks/999999999", ""},
+	{http.MethodDelete, "/api/tasks/999999999", ""},
+	{http.MethodPut, "/api/tasks/not-a-number", `{"title": "E2E"}`},
+	{http.MethodPost, "/api/tasks", `{"title": `},
+	{http.MethodPost, "/api/tasks", `{}`},
+	{http.MethodGet, "/api/rewards/orders/999999999", ""},
+	{http.MethodGet, "/api/e2e-missing-route", ""},
+}
+
+// errorEnvelope is the standard API error body, sent as is or wrapped in
+// {"error": ...}. Detail is FastAPI's default body for older backends.
+type errorEnvelope struct {
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Details   json.RawMessage `json:"details"`
+	RequestID string          `json:"request_id"`
+	Detail    interface{}     `json:"detail"`
+}
+
+// text returns the human readable message of the error.
+func (e errorEnvelope) text() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if detail, ok := e.Detail.(string); ok {
+		return detail
+	}
+	return ""
+}
+
+func decodeEnvelope(body []byte) (errorEnvelope, error) {
+	var wrapped struct {
+		Error *errorEnvelope `json:"error"`
+	}
+	if err := json.Unmarshal(body, &wrapped); err == nil && wrapped.Error != nil {
+		return *wrapped.Error, nil
+	}
+	var env errorEnvelope
+	err := json.Unmarshal(body, &env)
+	return env, err
+}
+
+// envelopeDeviations lists how a 4xx response differs from the envelope.
+func envelopeDeviations(resp *http.Response, body []byte) []string {
+	env, err := decodeEnvelope(body)
+	if err != nil {
+		return []string{"tělo není JSON objekt"}
+	}
+	var deviations []string
+	if env.Code == "" {
+		deviations = append(deviations, "chybí code")
+	}
+	if env.Message == "" {
+		deviations = append(deviations, "chybí message")
+	}
+	if env.Details == nil {
+		deviations = append(deviations, "chybí details")
+	}
+	switch header := resp.Header.Get("X-Request-ID"); {
+	case env.RequestID == "":
+		deviations = append(deviations, "chybí request_id")
+	case header != "" && header != env.RequestID:
+		deviations = append(deviations, fmt.Sprintf("request_id %s nesouhlasí s X-Request-ID %s", env.RequestID, header))
+	}
+	return deviations
+}
+
+// testErrorEnvelope triggers client errors across endpoints and checks that
+// each response carries code, message, details and request_id, which the
+// frontend's error handling relies on.
+func testErrorEnvelope(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🧾 TEST: Error Envelope")
+
+	var errs []error
+	var badStatus, deviating []string
+	for _, probe := range envelopeProbes {
+		target := probe.method + " " + probe.path
+		req, _ := http.NewRequest(probe.method, rc.Config.BackendURL+probe.path, strings.NewReader(probe.body))
+		if probe.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+			target += " " + probe.body
+		}
+		resp, err := rc.Client.Do(req)
+		if err != nil {
+			errs = append(errs, requestFailure(target, err))
+			continue
+		}
+		body, err := readBody(rc, resp)
+		resp.Body.Close()
+		if err != nil {
+			errs = append(errs, requestFailure(target, err))
+			continue
+		}
+		if resp.StatusCode < 400 || resp.StatusCode >= 500 {
+			fmt.Fprintf(rc.Out, "   ❌ %s: status %d místo 4xx\n", target, resp.StatusCode)
+			badStatus = append(badStatus, fmt.Sprintf("%s (%d)", target, resp.StatusCode))
+			continue
+		}
+		if deviations := envelopeDeviations(resp, body); len(deviations) > 0 {
+			fmt.Fprintf(rc.Out, "   ❌ %s (%d): %s\n", target, resp.StatusCode, strings.Join(deviations, ", "))
+			deviating = append(deviating, target)
+			continue
+		}
+		fmt.Fprintf(rc.Out, "   ✅ %s (%d)\n", target, resp.StatusCode)
+	}
+	if len(badStatus) > 0 {
+		errs = append(errs, failf(ReasonBadStatus, "Chybný vstup nevrací 4xx: %s", strings.Join(badStatus, ", ")))
+	}
+	if len(deviating) > 0 {
+		errs = append(errs, failf(ReasonSchema, "%d/%d chybových odpovědí nedodržuje envelope: %s", len(deviating), len(envelopeProbes), strings.Join(deviating, ", ")))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	fmt.Fprintf(rc.Out, "✅ Všech %d chybových odpovědí dodržuje envelope\n", len(envelopeProbes))
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// conditionalGet issues a GET with If-None-Match and returns the status, the
+// ETag of the response and its body.
+func conditionalGet(rc *RunContext, url, etag string) (int, string, []byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := rc.Client.Do(req)
+	if err != nil {
+		return 0, "", nil, requestFailure("GET "+url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return resp.StatusCode, "", nil, requestFailure("GET "+url, err)
+	}
+	return resp.StatusCode, resp.Header.Get("ETag"), body, nil
+}
+
+func updateTaskTitle(rc *RunContext, id int, title string) error {
+	payload, _ := json.Marshal(map[string]string{"title": title})
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/tasks/%d", rc.Config.BackendURL, id), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := rc.Client.Do(req)
+	if err != nil {
+		return requestFailure("PUT /api/tasks", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return failf(ReasonBadStatus, "PUT /api/tasks: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// checkETagInvalidation verifies url answers 304 for its current ETag before
+// mutate runs and a fresh 200 with a new ETag and containing marker after.
+func checkETagInvalidation(rc *RunContext, label, url string, mutate func() error, marker string) error {
+	status, etag, _, err := conditionalGet(rc, url, "")
+	if err != nil {
+		return fmt.Errorf("%s - načtení selhalo: %w", label, err)
+	}
+	if status != http.StatusOK {
+		return failf(ReasonBadStatus, "%s - načtení vrátilo status %d", label, status)
+	}
+	if etag == "" {
+		return failf(ReasonAssertion, "%s - odpověď neobsahuje ETag", label)
+	}
+
+	status, _, _, err = conditionalGet(rc, url, etag)
+	if err != nil {
+		return fmt.Errorf("%s - podmíněné načtení selhalo: %w", label, err)
+	}
+	if status != http.StatusNotModified {
+		return failf(ReasonAssertion, "%s - If-None-Match s aktuálním ETagem vrací %d místo 304", label, status)
+	}
+
+	if err := mutate(); err != nil {
+		return fmt.Errorf("%s - změna dat selhala: %w", label, err)
+	}
+
+	status, newETag, body, err := conditionalGet(rc, url, etag)
+	if err != nil {
+		return fmt.Errorf("%s - načtení po změně selhalo: %w", label, err)
+	}
+	if status == http.StatusNotModified {
+		return failf(ReasonAssertion, "%s - starý ETag po změně stále vrací 304 (zastaralá cache)", label)
+	}
+	if newETag == etag {
+		return failf(ReasonAssertion, "%s - ETag se po změně nezměnil (%s)", label, etag)
+	}
+	if !strings.Contains(string(body), marker) {
+		return failf(ReasonAssertion, "%s - odpověď po změně neobsahuje nová data", label)
+	}
+
+	fmt.Fprintf(rc.Out, "✅ %s - ETag %s → %s\n", label, etag, newETag)
+	return nil
+}
+
+func testETagInvalidation(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🏷️ TEST: ETag Invalidation")
+
+	token := fmt.Sprintf("e2e%d", time.Now().UnixNano()%1000000)
+	id, err := createTask(rc, "ETag task "+token, "")
+	if err != nil {
+		return fmt.Errorf("Vytvoření tasku selhalo: %w", err)
+	}
+	defer deleteTask(rc, id)
+
+	taskURL := fmt.Sprintf("%s/api/tasks/%d", rc.Config.BackendURL, id)
+	taskErr := checkETagInvalidation(rc, "Task detail", taskURL, func() error {
+		return updateTaskTitle(rc, id, "ETag task "+token+" v2")
+	}, token+" v2")
+
+	// The listing is shared, so concurrent tests writing tasks would change
+	// its ETag between the two reads.
+	if rc.Config.Parallel > 1 {
+		fmt.Fprintln(rc.Out, "   Marketplace listing přeskočen: při -parallel ho mění souběžné testy")
+		return taskErr
+	}
+	endpoint, err := rc.endpoint(EndpointMarketplace)
+	if err != nil {
+		return errors.Join(taskErr, err)
+	}
+	listingErr := checkETagInvalidation(rc, "Marketplace listing", endpoint, func() error {
+		return updateTaskTitle(rc, id, "ETag task "+token+" v3")
+	}, token+" v3")
+
+	return errors.Join(taskErr, listingErr)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// expiryDeadline is how far in the future the scratch task's due date is.
+const expiryDeadline = 2 * time.Second
+
+func inMarketplace(rc *RunContext, id int) (bool, error) {
+	endpoint, err := rc.endpoint(EndpointMarketplace)
+	if err != nil {
+		return false, err
+	}
+	var tasks []struct {
+		ID int `json:"id"`
+	}
+	if _, err := fetchJSON(rc, endpoint, &tasks); err != nil {
+		return false, err
+	}
+	for _, t := range tasks {
+		if t.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// claimTask tries to self-assign the task and returns the status.
+func claimTask(rc *RunContext, id int) (int, error) {
+	url := fmt.Sprintf("%s/api/tasks/%d/assign-to-me", rc.Config.BackendURL, id)
+	payload, _ := json.Marshal(map[string]string{"user_id": "e2e-expiry", "user_name": "E2E Expiry"})
+	resp, err := rc.Client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return 0, requestFailure("POST "+url, err)
+	}
+	discardBody(rc, resp)
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// testTaskExpiry creates a task due in a few seconds and checks that once the
+// deadline passes it leaves the marketplace, cannot be claimed and its owner
+// is notified. The backend gets -expiry-grace to process the expiry.
+func testTaskExpiry(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n⌛ TEST: Task Expiry")
+
+	due := time.Now().Add(expiryDeadline).UTC()
+	id, err := createTaskWith(rc, map[string]string{
+		"title":       "E2E expiring task",
+		"description": "Task pro test expirace",
+		"due_date":    due.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("Vytvoření tasku selhalo: %w", err)
+	}
+	defer deleteTask(rc, id)
+
+	listed, err := inMarketplace(rc, id)
+	if err != nil {
+		return fmt.Errorf("Marketplace - %w", err)
+	}
+	if !listed {
+		return failf(ReasonAssertion, "Task %d chybí v marketplace ještě před termínem", id)
+	}
+	fmt.Fprintf(rc.Out, "   Task %d s termínem %s je v marketplace\n", id, timestamp(rc.Config, due))
+
+	time.Sleep(time.Until(due))
+	grace := time.Now().Add(rc.Config.ExpiryGrace)
+	for {
+		listed, err = inMarketplace(rc, id)
+		if err != nil {
+			return fmt.Errorf("Marketplace - %w", err)
+		}
+		if !listed {
+			break
+		}
+		if time.Now().After(grace) {
+			return failf(ReasonAssertion, "Task %d je v marketplace ještě %s po termínu", id, rc.Config.ExpiryGrace)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	fmt.Fprintf(rc.Out, "✅ Expirovaný task zmizel z marketplace\n")
+
+	var errs []error
+	status, err := claimTask(rc, id)
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Errorf("Claim expirovaného tasku - %w", err))
+	case status < http.StatusBadRequest || status >= http.StatusInternalServerError:
+		errs = append(errs, failf(ReasonAssertion, "Claim expirovaného tasku %d nebyl odmítnut (status %d)", id, status))
+	default:
+		fmt.Fprintf(rc.Out, "✅ Claim expirovaného tasku odmítnut (status %d)\n", status)
+	}
+
+	notified, err := hasNotification(rc, func(n userNotification) bool {
+		return n.TaskID != nil && *n.TaskID == id && strings.Contains(strings.ToLower(n.Type), "expir")
+	})
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Errorf("Notifikace o expiraci - %w", err))
+	case !notified:
+		errs = append(errs, failf(ReasonAssertion, "Vlastník nedostal notifikaci o expiraci tasku %d", id))
+	default:
+		fmt.Fprintf(rc.Out, "✅ Vlastník dostal notifikaci o expiraci\n")
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+)
+
+func downloadExport(rc *RunContext, format string) ([]byte, string, error) {
+	resp, err := rc.Client.Get(rc.Config.BackendURL + rc.Config.ExportPath + "?format=" + format)
+	if err != nil {
+		return nil, "", requestFailure("GET "+rc.Config.ExportPath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", failf(ReasonBadStatus, "GET %s: status %d", rc.Config.ExportPath, resp.StatusCode)
+	}
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return nil, "", requestFailure("GET "+rc.Config.ExportPath, err)
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
+
+// validateJSONExport checks the top-level structure of a JSON export and
+// returns the number of records in every list section.
+func validateJSONExport(data []byte) (map[string]int, error) {
+	var export map[string]json.RawMessage
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("neplatný JSON: %v", err)
+	}
+
+	var user map[string]interface{}
+	if err := json.Unmarshal(export["user"], &user); err != nil || user == nil {
+		return nil, fmt.Errorf("chybí sekce user")
+	}
+	if _, ok := export["tasks"]; !ok {
+		return nil, fmt.Errorf("chybí sekce tasks")
+	}
+
+	counts := map[string]int{}
+	for key, raw := range export {
+		var list []json.RawMessage
+		if err := json.Unmarshal(raw, &list); err == nil {
+			counts[key] = len(list)
+		}
+	}
+	return counts, nil
+}
+
+func validateCSVExport(data []byte) (int, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return 0, fmt.Errorf("neplatné CSV: %v", err)
+	}
+	if len(records) == 0 {
+		return 0, fmt.Errorf("CSV neobsahuje hlavičku")
+	}
+
+	columns := map[string]bool{}
+	for _, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	for _, required := range []string{"id", "title"} {
+		if !columns[required] {
+			return 0, fmt.Errorf("CSV hlavička neobsahuje sloupec %s", required)
+		}
+	}
+	return len(records) - 1, nil
+}
+
+// validateZipExport validates every JSON and CSV entry of an export archive.
+// Entries are read capped at limit to guard against decompression bombs.
+func validateZipExport(data []byte, limit int64) error {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("neplatný ZIP: %v", err)
+	}
+
+	validated := 0
+	for _, file := range archive.File {
+		ext := strings.ToLower(path.Ext(file.Name))
+		if ext != ".json" && ext != ".csv" {
+			continue
+		}
+
+		r, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %v", file.Name, err)
+		}
+		content, err := readLimited(r, limit)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", file.Name, err)
+		}
+
+		if ext == ".csv" {
+			_, err = validateCSVExport(content)
+		} else if !json.Valid(content) {
+			err = fmt.Errorf("neplatný JSON")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", file.Name, err)
+		}
+		validated++
+	}
+
+	if validated == 0 {
+		return fmt.Errorf("archiv neobsahuje žádný JSON ani CSV soubor")
+	}
+	return nil
+}
+
+func testUserDataExport(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n📦 TEST: User Data Export")
+
+	data, contentType, err := downloadExport(rc, "json")
+	if err != nil {
+		return fmt.Errorf("JSON export selhal: %w", err)
+	}
+
+	var counts map[string]int
+	if strings.Contains(contentType, "zip") {
+		err = validateZipExport(data, rc.Config.MaxBodyBytes)
+	} else {
+		counts, err = validateJSONExport(data)
+	}
+	if err != nil {
+		return failf(ReasonSchema, "JSON export má neplatnou strukturu: %v", err)
+	}
+	fmt.Fprintf(rc.Out, "✅ JSON export validní (%d B, sekce: %v)\n", len(data), counts)
+
+	csvData, csvType, err := downloadExport(rc, "csv")
+	if err != nil {
+		return fmt.Errorf("CSV export selhal: %w", err)
+	}
+	if strings.Contains(csvType, "zip") {
+		err = validateZipExport(csvData, rc.Config.MaxBodyBytes)
+	} else {
+		var rows int
+		rows, err = validateCSVExport(csvData)
+		if err == nil {
+			fmt.Fprintf(rc.Out, "✅ CSV export validní (%d řádků)\n", rows)
+		}
+	}
+	if err != nil {
+		return failf(ReasonSchema, "CSV export má neplatnou strukturu: %v", err)
+	}
+
+	if rc.Config.ImportPath == "" || counts == nil {
+		fmt.Fprintln(rc.Out, "   Re-import přeskočen (není nastaven -import-path nebo export není JSON)")
+		return nil
+	}
+	return checkImportRoundTrip(rc, data, counts)
+}
+
+// checkImportRoundTrip re-imports a JSON export into a scratch account and
+// compares the imported record counts per section with the export.
+func checkImportRoundTrip(rc *RunContext, data []byte, counts map[string]int) error {
+	resp, err := rc.Client.Post(rc.Config.BackendURL+rc.Config.ImportPath, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return requestFailure("Re-import", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return failf(ReasonBadStatus, "Re-import vrátil status %d", resp.StatusCode)
+	}
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return requestFailure("Re-import", err)
+	}
+
+	var result struct {
+		UserID   interface{}    `json:"user_id"`
+		Imported map[string]int `json:"imported"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return failf(ReasonSchema, "Odpověď re-importu není platný JSON: %v", err)
+	}
+
+	var missing []string
+	for section, want := range counts {
+		if got := result.Imported[section]; got != want {
+			missing = append(missing, fmt.Sprintf("%s: %d z %d", section, got, want))
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return failf(ReasonAssertion, "Re-import neúplný (%s)", strings.Join(missing, ", "))
+	}
+	fmt.Fprintf(rc.Out, "✅ Re-import do scratch účtu %v kompletní\n", result.UserID)
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+)
+
+// FailureReason classifies why a test failed so reports and dashboards can
+// chart failure causes instead of bare counts.
+type FailureReason string
+
+const (
+	ReasonConnection FailureReason = "ConnectionError"
+	ReasonTimeout    FailureReason = "Timeout"
+	ReasonBadStatus  FailureReason = "BadStatus"
+	ReasonSchema     FailureReason = "SchemaMismatch"
+	ReasonAssertion  FailureReason = "AssertionFailed"
+	// ReasonHarness marks failures of the harness itself, not of the app.
+	ReasonHarness FailureReason = "HarnessError"
+)
+
+// TestFailure is the error a test returns when it fails. Wrapping it with
+// fmt.Errorf("...: %w", err) keeps the reason intact.
+type TestFailure struct {
+	Reason  FailureReason
+	Message string
+}
+
+func (f *TestFailure) Error() string {
+	return f.Message
+}
+
+func failf(reason FailureReason, format string, args ...interface{}) error {
+	return &TestFailure{Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
+// warningError marks a test that passed with a caveat, like a degraded
+// backend. It is reported but never fails the run.
+type warningError struct {
+	message string
+}
+
+func (e *warningError) Error() string {
+	return e.message
+}
+
+func warned(err error) bool {
+	var warning *warningError
+	return errors.As(err, &warning)
+}
+
+// renderWarnings lists tests that passed with a warning.
+func renderWarnings(results TestResult) string {
+	if len(results.Warnings) == 0 {
+		return ""
+	}
+	out := fmt.Sprintf("\n⚠️ VAROVÁNÍ (%d):\n", len(results.Warnings))
+	for _, o := range results.Outcomes {
+		if o.Warning {
+			out += fmt.Sprintf("  ⚠️ %s - %s\n", o.Name, o.Message)
+		}
+	}
+	return out
+}
+
+// requestFailure classifies an error from sending a request or reading its
+// body.
+func requestFailure(what string, err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errBodyTooLarge):
+		return failf(ReasonSchema, "%s: %v", what, err)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return failf(ReasonTimeout, "%s: %v", what, err)
+	default:
+		return failf(ReasonConnection, "%s: %v", what, err)
+	}
+}
+
+// reasonOf returns the failure reason carried by err. Errors that are not
+// classified count as failed assertions.
+func reasonOf(err error) FailureReason {
+	var failure *TestFailure
+	if errors.As(err, &failure) {
+		return failure.Reason
+	}
+	return ReasonAssertion
+}
+
+// reasonCounts returns the number of failures per reason, sorted by reason.
+func reasonCounts(outcomes []TestOutcome) []reasonCount {
+	counts := map[FailureReason]int{}
+	for _, o := range outcomes {
+		if !o.Passed && !o.Skipped {
+			counts[o.Reason]++
+		}
+	}
+
+	var list []reasonCount
+	for reason, n := range counts {
+		list = append(list, reasonCount{reason, n})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Reason < list[j].Reason })
+	return list
+}
+
+type reasonCount struct {
+	Reason FailureReason
+	Count  int
+}
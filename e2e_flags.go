@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// featureGates lists tests that only make sense while a backend feature flag
+// is on. A gated test is skipped when the flag is explicitly off and runs
+// when the flag is on or unknown. Opt-in features also skip when unknown.
+var featureGates = []struct {
+	test  string
+	flag  string
+	optIn bool
+}{
+	{"Search Relevance", "search", true},
+	{"User Data Export", "data_export", true},
+	{"Localization", "i18n", false},
+	{"Task Expiry", "task_expiry", false},
+	{"Avatar Upload", "avatar_upload", false},
+	{"Email Notifications", "email_notifications", false},
+	{"Admin Feature Task", "marketplace_featured", false},
+	{"Reward Redemption", "rewards_store", true},
+	{"Locale Formatting", "rewards_store", true},
+	{"Locale Formatting", "i18n", false},
+}
+
+// skipError marks a test that was not run. It is not a failure.
+type skipError struct {
+	reason string
+}
+
+func (e *skipError) Error() string {
+	return e.reason
+}
+
+func skipped(err error) bool {
+	var skip *skipError
+	return errors.As(err, &skip)
+}
+
+// fetchFeatureFlags reads the flag endpoint. Both {"flag": true} and
+// [{"name": "flag", "enabled": true}] are accepted.
+func fetchFeatureFlags(rc *RunContext) (map[string]bool, error) {
+	var raw json.RawMessage
+	if _, err := fetchJSON(rc, rc.Config.BackendURL+rc.Config.FlagsPath, &raw); err != nil {
+		return nil, err
+	}
+	flags := map[string]bool{}
+	if err := json.Unmarshal(raw, &flags); err == nil {
+		return flags, nil
+	}
+	var list []struct {
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+	}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, failf(ReasonSchema, "GET %s: neočekávaný formát feature flagů", rc.Config.FlagsPath)
+	}
+	for _, f := range list {
+		flags[f.Name] = f.Enabled
+	}
+	return flags, nil
+}
+
+// loadFeatureFlags takes the flag snapshot at run start and stores it in the
+// run state. A resumed run reuses the snapshot of the interrupted run, so
+// both halves gate the same tests.
+func loadFeatureFlags(rc *RunContext, state *RunState) map[string]bool {
+	if rc.Config.FlagsPath == "" {
+		return nil
+	}
+	if state.FeatureFlags != nil {
+		fmt.Printf("🚩 Feature flagy z přerušeného běhu: %s\n", formatFlags(state.FeatureFlags))
+		return state.FeatureFlags
+	}
+	flags, err := fetchFeatureFlags(rc)
+	if err != nil {
+		fmt.Printf("⚠️ Feature flagy nelze načíst, gated testy poběží a opt-in testy se přeskočí: %v\n", err)
+		return nil
+	}
+	state.FeatureFlags = flags
+	fmt.Printf("🚩 Feature flagy: %s\n", formatFlags(flags))
+	return flags
+}
+
+// gateTests replaces tests whose flag is off with a skipped placeholder.
+func gateTests(tests []testCase, flags map[string]bool) []testCase {
+	gated := make([]testCase, len(tests))
+	copy(gated, tests)
+	for i, test := range gated {
+		for _, gate := range featureGates {
+			if gate.test != baseTestName(test.name) {
+				continue
+			}
+			enabled, known := flags[gate.flag]
+			if known && !enabled || gate.optIn && !known {
+				reason := fmt.Sprintf("feature flag %s není zapnutý", gate.flag)
+				gated[i].fn = func(*RunContext) error { return &skipError{reason} }
+			}
+		}
+	}
+	return gated
+}
+
+func formatFlags(flags map[string]bool) string {
+	if len(flags) == 0 {
+		return "žádné"
+	}
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	items := make([]string, len(names))
+	for i, name := range names {
+		state := "off"
+		if flags[name] {
+			state = "on"
+		}
+		items[i] = name + "=" + state
+	}
+	return strings.Join(items, ", ")
+}
+
+// renderSkipped lists skipped tests with their reason and the flag snapshot.
+func renderSkipped(results TestResult) string {
+	out := ""
+	if len(results.Skipped) > 0 {
+		out += fmt.Sprintf("\n⏭️ PŘESKOČENO (%d):\n", len(results.Skipped))
+		for _, o := range results.Outcomes {
+			if o.Skipped {
+				out += fmt.Sprintf("  ⏭️ %s - %s\n", o.Name, o.Message)
+			}
+		}
+	}
+	if results.FeatureFlags != nil {
+		out += fmt.Sprintf("\n🚩 Feature flagy: %s\n", formatFlags(results.FeatureFlags))
+	}
+	return out
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// apiBaseKeys are the runtime config keys the frontend may use for its API base.
+var apiBaseKeys = []string{"VITE_API_URL", "API_URL", "apiUrl", "apiBase", "apiBaseUrl"}
+
+var (
+	windowEnvRe = regexp.MustCompile(`window\.__ENV__\s*=\s*(\{[^<]*?\})\s*;?\s*</script>`)
+	// Vite dev server injects import.meta.env into every transformed module.
+	viteEnvRe   = regexp.MustCompile(`"VITE_API_URL"\s*:\s*"([^"]+)"`)
+	scriptSrcRe = regexp.MustCompile(`<script[^>]+src="([^"]+\.js)"`)
+	apiURLRe    = regexp.MustCompile(`https?://[^"'\x60\s]+/api\b`)
+)
+
+// defaultFrontendAPIBase is the fallback hard-coded in the frontend composables.
+const defaultFrontendAPIBase = "http://localhost:8000/api"
+
+func fetchFrontend(rc *RunContext, path string) (int, []byte, error) {
+	resp, err := rc.Client.Get(strings.TrimSuffix(rc.Config.FrontendURL, "/") + path)
+	if err != nil {
+		return 0, nil, requestFailure("GET "+path, err)
+	}
+	defer resp.Body.Close()
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return resp.StatusCode, nil, requestFailure("GET "+path, err)
+	}
+	return resp.StatusCode, body, nil
+}
+
+func apiBaseFromJSON(data []byte) string {
+	var config map[string]interface{}
+	if json.Unmarshal(data, &config) != nil {
+		return ""
+	}
+	for _, key := range apiBaseKeys {
+		if value, ok := config[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// discoverAPIBase finds the API base the deployed frontend will call. It
+// tries /config.json, window.__ENV__ in index.html, the env injected by the
+// Vite dev server and finally URLs baked into the production bundles.
+func discoverAPIBase(rc *RunContext) (base, source string, err error) {
+	if status, body, err := fetchFrontend(rc, "/config.json"); err == nil && status == http.StatusOK {
+		if base := apiBaseFromJSON(body); base != "" {
+			return base, "/config.json", nil
+		}
+	}
+
+	status, index, err := fetchFrontend(rc, "/")
+	if err != nil {
+		return "", "", err
+	}
+	if status != http.StatusOK {
+		return "", "", failf(ReasonBadStatus, "GET /: status %d", status)
+	}
+	if m := windowEnvRe.FindSubmatch(index); m != nil {
+		if base := apiBaseFromJSON(m[1]); base != "" {
+			return base, "window.__ENV__", nil
+		}
+	}
+
+	if status, module, err := fetchFrontend(rc, "/src/composables/useApi.ts"); err == nil && status == http.StatusOK {
+		if m := viteEnvRe.FindSubmatch(module); m != nil {
+			return string(m[1]), "Vite dev env", nil
+		}
+		if strings.Contains(string(module), "import.meta.env") {
+			return defaultFrontendAPIBase, "Vite dev výchozí hodnota", nil
+		}
+	}
+
+	for _, m := range scriptSrcRe.FindAllSubmatch(index, -1) {
+		src := string(m[1])
+		if strings.HasPrefix(src, "http") {
+			continue
+		}
+		status, bundle, err := fetchFrontend(rc, "/"+strings.TrimPrefix(src, "/"))
+		if err != nil || status != http.StatusOK {
+			continue
+		}
+		if found := apiURLRe.Find(bundle); found != nil {
+			return string(found), src, nil
+		}
+	}
+	return "", "", failf(ReasonAssertion, "API base frontendu nelze zjistit (config.json, window.__ENV__, bundly)")
+}
+
+// sameOrigin compares scheme, host and port of two URLs.
+func sameOrigin(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Scheme, ub.Scheme) && strings.EqualFold(ua.Host, ub.Host)
+}
+
+// testFrontendAPIBase catches a frontend deployed against the wrong backend,
+// e.g. a staging frontend still pointing at production.
+func testFrontendAPIBase(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🔗 TEST: Frontend API Base")
+
+	base, source, err := discoverAPIBase(rc)
+	if err != nil {
+		return fmt.Errorf("Frontend API base - %w", err)
+	}
+	fmt.Fprintf(rc.Out, "   API base: %s (zdroj: %s)\n", base, source)
+
+	if strings.HasPrefix(base, "/") {
+		fmt.Fprintf(rc.Out, "✅ Frontend volá API relativně přes vlastní origin (proxy), backend nelze porovnat\n")
+		return nil
+	}
+	if !sameOrigin(base, rc.Config.BackendURL) {
+		return failf(ReasonAssertion, "Frontend API base %s neodpovídá testovanému backendu %s", base, rc.Config.BackendURL)
+	}
+	fmt.Fprintf(rc.Out, "✅ Frontend míří na testovaný backend\n")
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HealthResponse is the /health body. Status is ok, degraded or down.
+// Components come from a "components" object or, in the older flat format,
+// from the other top-level string fields like "database": "ok".
+type HealthResponse struct {
+	Status     string
+	Components map[string]HealthComponent
+}
+
+// HealthComponent is one dependency of the backend. It may be given as a
+// bare status string or as an object.
+type HealthComponent struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+func (h *HealthResponse) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw["status"], &h.Status); err != nil {
+		return fmt.Errorf("status: %w", err)
+	}
+	h.Components = map[string]HealthComponent{}
+	if components, ok := raw["components"]; ok {
+		return json.Unmarshal(components, &h.Components)
+	}
+	for name, value := range raw {
+		var c HealthComponent
+		if name != "status" && c.UnmarshalJSON(value) == nil {
+			h.Components[name] = c
+		}
+	}
+	return nil
+}
+
+func (c *HealthComponent) UnmarshalJSON(data []byte) error {
+	var status string
+	if err := json.Unmarshal(data, &status); err == nil {
+		// The flat format puts the message after the state: "error: ...".
+		c.Status, c.Message, _ = strings.Cut(status, ": ")
+		return nil
+	}
+	type plain HealthComponent
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+func (c HealthComponent) healthy() bool {
+	switch strings.ToLower(c.Status) {
+	case "ok", "up", "healthy", "active":
+		return true
+	}
+	return false
+}
+
+// unhealthy lists the components that are not ok, sorted by name.
+func (h HealthResponse) unhealthy() []string {
+	var list []string
+	for name, c := range h.Components {
+		if c.healthy() {
+			continue
+		}
+		item := name + "=" + c.Status
+		if c.Message != "" {
+			item += " (" + c.Message + ")"
+		}
+		list = append(list, item)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+var czechWords = map[string]bool{
+	"je": true, "na": true, "si": true, "byl": true, "byla": true, "bylo": true,
+	"nenalezen": true, "nenalezena": true, "úkol": true, "bodů": true, "vzala": true, "vzal": true,
+}
+
+var englishWords = map[string]bool{
+	"the": true, "is": true, "was": true, "not": true, "found": true, "has": true,
+	"claimed": true, "points": true, "new": true, "task": true, "completed": true,
+}
+
+// detectLanguage guesses whether text is Czech or English from diacritics
+// and common words. It returns "" when it cannot tell.
+func detectLanguage(text string) string {
+	if strings.ContainsAny(strings.ToLower(text), "áčďéěíňóřšťúůýž") {
+		return "cs"
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	cs, en := 0, 0
+	for _, w := range words {
+		if czechWords[w] {
+			cs++
+		}
+		if englishWords[w] {
+			en++
+		}
+	}
+	switch {
+	case cs > en:
+		return "cs"
+	case en > cs:
+		return "en"
+	}
+	return ""
+}
+
+// localizedRequest sends a request with Accept-Language and decodes the JSON
+// body into v. Content-Language, when sent, must match the requested language.
+func localizedRequest(rc *RunContext, method, url, lang string, wantStatus int, v interface{}) error {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept-Language", lang)
+
+	resp, err := rc.Client.Do(req)
+	if err != nil {
+		return requestFailure(method+" "+url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return requestFailure(method+" "+url, err)
+	}
+	if resp.StatusCode != wantStatus {
+		return failf(ReasonBadStatus, "%s %s: status %d místo %d", method, url, resp.StatusCode, wantStatus)
+	}
+	if cl := resp.Header.Get("Content-Language"); cl != "" && !strings.HasPrefix(cl, lang) {
+		return failf(ReasonAssertion, "%s %s: Content-Language %s místo %s", method, url, cl, lang)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return failf(ReasonSchema, "%s %s: neplatný JSON: %v", method, url, err)
+	}
+	return nil
+}
+
+func checkLanguage(label, lang, text string) error {
+	got := detectLanguage(text)
+	if got != lang {
+		if got == "" {
+			got = "neurčený"
+		}
+		return failf(ReasonAssertion, "%s [%s]: text %q je v jazyce %s", label, lang, text, got)
+	}
+	return nil
+}
+
+// testLocalization asks for Czech and English and checks that error details
+// and notification texts come back in the requested language.
+func testLocalization(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🌐 TEST: Localization (Accept-Language)")
+
+	var errs []error
+	for _, lang := range []string{"cs", "en"} {
+		var apiErr errorEnvelope
+		err := localizedRequest(rc, http.MethodGet, rc.Config.BackendURL+"/api/tasks/999999999", lang, http.StatusNotFound, &apiErr)
+		if err == nil {
+			err = checkLanguage("Chybová hláška", lang, apiErr.text())
+		}
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			fmt.Fprintf(rc.Out, "✅ Chybová hláška [%s]: %s\n", lang, apiErr.text())
+		}
+
+		var notification struct {
+			Title string `json:"title"`
+		}
+		err = localizedRequest(rc, http.MethodPost, rc.Config.BackendURL+"/api/notifications/test/create-sample", lang, http.StatusOK, &notification)
+		if err == nil {
+			err = checkLanguage("Notifikace", lang, notification.Title)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			fmt.Fprintf(rc.Out, "✅ Notifikace [%s]: %s\n", lang, notification.Title)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// numberFormats are the separators a locale must use in formatted amounts.
+// Czech groups with a no-break space, plain or narrow, so the mobile client
+// never wraps an amount.
+var numberFormats = map[string]struct {
+	groups  []string
+	decimal string
+}{
+	"cs": {[]string{"\u00a0", "\u202f"}, ","},
+	"en": {[]string{","}, "."},
+}
+
+var currencySymbols = []string{"Kč", "CZK", "€", "EUR", "$", "USD"}
+
+// formattedField is a "<name>_formatted" string next to its numeric "<name>".
+type formattedField struct {
+	path      string
+	value     float64
+	formatted string
+}
+
+func collectFormatted(v interface{}, path string, out *[]formattedField) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if s, ok := value.(string); ok && strings.HasSuffix(key, "_formatted") {
+				if n, ok := v[strings.TrimSuffix(key, "_formatted")].(float64); ok {
+					*out = append(*out, formattedField{path + "." + key, n, s})
+				}
+				continue
+			}
+			collectFormatted(value, path+"."+key, out)
+		}
+	case []interface{}:
+		for i, item := range v {
+			collectFormatted(item, fmt.Sprintf("%s[%d]", path, i), out)
+		}
+	}
+}
+
+// groupDigits formats the integer part of value with the given group
+// separator, e.g. 1234567 as 1,234,567.
+func groupDigits(value float64, group string) string {
+	digits := fmt.Sprintf("%.0f", math.Trunc(math.Abs(value)))
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(group)
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// checkFormatted checks the separators of a formatted amount against its
+// number, and for currencies the side of the symbol: after the number in
+// Czech ("1 234,50 Kč"), before it in English ("CZK 1,234.50").
+func checkFormatted(lang string, f formattedField) error {
+	format := numberFormats[lang]
+	text := strings.TrimSpace(f.formatted)
+
+	var expected []string
+	for _, group := range format.groups {
+		number := groupDigits(f.value, group)
+		if f.value != math.Trunc(f.value) || strings.Contains(text, format.decimal) {
+			cents := int(math.Round(math.Abs(f.value)*100)) % 100
+			number += fmt.Sprintf("%s%02d", format.decimal, cents)
+		}
+		expected = append(expected, number)
+	}
+	at := -1
+	for _, number := range expected {
+		if at = strings.Index(text, number); at >= 0 {
+			expected = []string{number}
+			break
+		}
+	}
+	if at < 0 {
+		return failf(ReasonAssertion, "%s [%s]: %q neobsahuje číslo %v ve tvaru %q", f.path, lang, f.formatted, f.value, expected[0])
+	}
+
+	for _, symbol := range currencySymbols {
+		pos := strings.Index(text, symbol)
+		if pos < 0 {
+			continue
+		}
+		if lang == "cs" && pos < at || lang != "cs" && pos > at {
+			return failf(ReasonAssertion, "%s [%s]: měna %s je v %q na špatné straně čísla", f.path, lang, symbol, f.formatted)
+		}
+		break
+	}
+	return nil
+}
+
+// testLocaleFormatting asks the rewards endpoints for Czech and English and
+// checks every formatted amount, because the mobile client shows these
+// strings verbatim.
+func testLocaleFormatting(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🔢 TEST: Locale Formatting")
+
+	var errs []error
+	checked := 0
+	for _, lang := range []string{"cs", "en"} {
+		for _, path := range []string{"/api/rewards", "/api/rewards/balance"} {
+			var body interface{}
+			if err := localizedRequest(rc, http.MethodGet, rc.Config.BackendURL+path, lang, http.StatusOK, &body); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			var fields []formattedField
+			collectFormatted(body, path, &fields)
+			for _, f := range fields {
+				checked++
+				if err := checkFormatted(lang, f); err != nil {
+					errs = append(errs, err)
+				} else {
+					fmt.Fprintf(rc.Out, "✅ %s [%s]: %q\n", f.path, lang, f.formatted)
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if checked == 0 {
+		return &skipError{"odměny neobsahují žádná pole *_formatted"}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jobPollInterval is used when the status response has no Retry-After.
+const jobPollInterval = 500 * time.Millisecond
+
+// jobTerminal lists job states after which the status no longer changes.
+var jobTerminal = map[string]bool{
+	"completed": true, "done": true, "succeeded": true, "success": true,
+	"partial": true, "failed": true, "error": true, "cancelled": true, "canceled": true,
+}
+
+// jobLocation resolves the status URL of a job accepted by target: the
+// Location header, then status_url in the body, then prefix/job_id.
+func jobLocation(rc *RunContext, target string, resp *http.Response, body []byte, prefix string) (string, error) {
+	var job struct {
+		JobID     string `json:"job_id"`
+		StatusURL string `json:"status_url"`
+	}
+	json.Unmarshal(body, &job)
+
+	status := resp.Header.Get("Location")
+	if status == "" {
+		status = job.StatusURL
+	}
+	if status == "" && job.JobID != "" && prefix != "" {
+		status = prefix + "/" + url.PathEscape(job.JobID)
+	}
+	if status == "" {
+		return "", failf(ReasonSchema, "%s: odpověď neobsahuje Location, status_url ani job_id", target)
+	}
+	ref, err := url.Parse(status)
+	if err != nil {
+		return "", failf(ReasonSchema, "%s: neplatná status URL %q", target, status)
+	}
+	return resp.Request.URL.ResolveReference(ref).String(), nil
+}
+
+// retryAfter parses Retry-After as seconds or an HTTP date.
+func retryAfter(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	if secs, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return fallback
+}
+
+// followJob polls a job status URL until the job reaches a terminal state and
+// decodes the final response into v. The status answers 202 or a non-terminal
+// status while running; a redirect to the result also ends the job. Polls wait
+// as long as Retry-After asks, but never past -job-deadline.
+func followJob(rc *RunContext, statusURL string, v interface{}) (string, error) {
+	start := time.Now()
+	deadline := start.Add(rc.Config.JobDeadline)
+	for polls := 1; ; polls++ {
+		resp, err := rc.Client.Get(statusURL)
+		if err != nil {
+			return "", requestFailure("GET "+statusURL, err)
+		}
+		body, err := readBody(rc, resp)
+		resp.Body.Close()
+		if err != nil {
+			return "", requestFailure("GET "+statusURL, err)
+		}
+		var state struct {
+			Status string `json:"status"`
+		}
+		json.Unmarshal(body, &state)
+		status := strings.ToLower(state.Status)
+
+		switch {
+		case resp.StatusCode == http.StatusAccepted:
+		case resp.StatusCode != http.StatusOK:
+			return status, failf(ReasonBadStatus, "GET %s: status %d", statusURL, resp.StatusCode)
+		case jobTerminal[status] || status == "" && resp.Request.URL.String() != statusURL:
+			if v != nil {
+				if err := json.Unmarshal(body, v); err != nil {
+					return status, failf(ReasonSchema, "GET %s: neplatný JSON: %v", statusURL, err)
+				}
+			}
+			fmt.Fprintf(rc.Out, "   Job dokončen (%s) po %d dotazech za %s\n", state.Status, polls, time.Since(start).Round(time.Millisecond))
+			return status, nil
+		case status == "":
+			return status, failf(ReasonSchema, "GET %s: stav jobu neobsahuje status", statusURL)
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return status, failf(ReasonTimeout, "Job %s je ve stavu %q ani po %s", statusURL, state.Status, rc.Config.JobDeadline)
+		}
+		time.Sleep(min(retryAfter(resp.Header.Get("Retry-After"), jobPollInterval), remaining))
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JourneyStep is one read-only request of a user journey. Frontend steps
+// are resolved against the frontend URL, all others against the backend.
+type JourneyStep struct {
+	Name     string
+	Path     string
+	Frontend bool
+}
+
+// Journey is a named user scenario. Weight is its relative share of traffic
+// in load mode; functional mode runs every selected journey once. Role, when
+// set, is the profile role whose client runs the backend steps.
+type Journey struct {
+	Name   string
+	Weight int
+	Role   string
+	Steps  []JourneyStep
+}
+
+var journeyLibrary = []Journey{
+	{
+		Name:   "onboarding",
+		Weight: 2,
+		Steps: []JourneyStep{
+			{Name: "landing page", Path: "/", Frontend: true},
+			{Name: "health", Path: "/health"},
+			{Name: "projekty", Path: "/api/projects"},
+			{Name: "marketplace", Path: "/api/tasks/marketplace"},
+			{Name: "týdenní žebříček", Path: "/api/leaderboard/weekly"},
+			{Name: "nepřečtené notifikace", Path: "/api/notifications/unread-count"},
+		},
+	},
+	{
+		Name:   "power-user",
+		Weight: 5,
+		Steps: []JourneyStep{
+			{Name: "dashboard", Path: "/api/dashboard"},
+			{Name: "marketplace", Path: "/api/tasks/marketplace"},
+			{Name: "tasky", Path: "/api/tasks"},
+			{Name: "týdenní žebříček", Path: "/api/leaderboard/weekly"},
+			{Name: "měsíční žebříček", Path: "/api/leaderboard/monthly"},
+			{Name: "notifikace", Path: "/api/notifications/poll"},
+			{Name: "event feed", Path: "/api/events/feed"},
+		},
+	},
+	{
+		Name:   "admin-moderation",
+		Weight: 1,
+		Role:   RoleAdmin,
+		Steps: []JourneyStep{
+			{Name: "audit log", Path: "/api/audit"},
+			{Name: "audit statistiky", Path: "/api/audit/stats"},
+			{Name: "incidenty", Path: "/api/incidents"},
+			{Name: "otevřené incidenty", Path: "/api/incidents/open"},
+			{Name: "SLA report", Path: "/api/sla/report"},
+			{Name: "monitory", Path: "/api/monitors"},
+		},
+	},
+}
+
+// selectJourneys resolves a "name[=weight],..." list against the library.
+// An empty spec selects every journey with its default weight.
+func selectJourneys(spec string) ([]Journey, error) {
+	if spec == "" {
+		return journeyLibrary, nil
+	}
+
+	var selected []Journey
+	for _, item := range strings.Split(spec, ",") {
+		name, weight, hasWeight := strings.Cut(strings.TrimSpace(item), "=")
+		journey, ok := findJourney(name)
+		if !ok {
+			return nil, fmt.Errorf("neznámá journey: %s", name)
+		}
+		if hasWeight {
+			w, err := strconv.Atoi(weight)
+			if err != nil || w < 0 {
+				return nil, fmt.Errorf("neplatná váha journey %s: %s", name, weight)
+			}
+			journey.Weight = w
+		}
+		selected = append(selected, journey)
+	}
+	return selected, nil
+}
+
+// hasRole reports whether the profile defines the role j runs as.
+func hasRole(rc *RunContext, j Journey) bool {
+	_, ok := rc.Roles[j.Role]
+	return j.Role == "" || ok
+}
+
+func findJourney(name string) (Journey, bool) {
+	for _, j := range journeyLibrary {
+		if j.Name == name {
+			return j, true
+		}
+	}
+	return Journey{}, false
+}
+
+type stepResult struct {
+	Step     string
+	Status   int
+	Err      error
+	Duration time.Duration
+}
+
+func (r stepResult) ok() bool {
+	return r.Err == nil && r.Status == http.StatusOK
+}
+
+// runJourney executes all steps of j and stops at the first failing step.
+func runJourney(rc *RunContext, j Journey) []stepResult {
+	var results []stepResult
+	for _, step := range j.Steps {
+		base, client := rc.Config.BackendURL, rc.Client
+		if step.Frontend {
+			base = rc.Config.FrontendURL
+		} else if j.Role != "" {
+			client = rc.Roles[j.Role]
+		}
+
+		start := time.Now()
+		result := stepResult{Step: step.Name}
+		resp, err := client.Get(base + step.Path)
+		if err != nil {
+			result.Err = err
+		} else {
+			discardBody(rc, resp)
+			resp.Body.Close()
+			result.Status = resp.StatusCode
+		}
+		result.Duration = time.Since(start)
+
+		results = append(results, result)
+		if !result.ok() {
+			break
+		}
+	}
+	return results
+}
+
+// journeyTest wraps a journey as a functional test.
+func journeyTest(j Journey) testCase {
+	return testCase{
+		name:     "Journey: " + j.Name,
+		severity: SeverityMajor,
+		suite:    SuiteJourneys,
+		fn: func(rc *RunContext) error {
+			fmt.Fprintf(rc.Out, "\n🧭 JOURNEY: %s\n", j.Name)
+			if !hasRole(rc, j) {
+				return &skipError{fmt.Sprintf("profil nemá roli %s", j.Role)}
+			}
+			results := runJourney(rc, j)
+			for _, r := range results {
+				if r.Err != nil {
+					return requestFailure("Krok "+r.Step, r.Err)
+				}
+				if !r.ok() {
+					return failf(ReasonBadStatus, "Krok %s vrátil status %d", r.Step, r.Status)
+				}
+				fmt.Fprintf(rc.Out, "✅ %s (%s)\n", r.Step, r.Duration.Round(time.Millisecond))
+			}
+			return nil
+		},
+	}
+}
+
+// pickJourney chooses a journey at random proportionally to its weight.
+func pickJourney(rnd *rand.Rand, journeys []Journey, totalWeight int) Journey {
+	n := rnd.Intn(totalWeight)
+	for _, j := range journeys {
+		if n < j.Weight {
+			return j
+		}
+		n -= j.Weight
+	}
+	return journeys[len(journeys)-1]
+}
+
+type journeyStats struct {
+	Runs      int
+	Errors    int
+	Durations durationHistogram
+}
+
+// runLoad runs weighted journeys from concurrent workers for the configured
+// duration. Workers report every finished journey to a single collector,
+// which owns the stats and snapshots them on every heartbeat.
+func runLoad(rc *RunContext, journeys []Journey) (map[string]*journeyStats, []loadSnapshot) {
+	totalWeight := 0
+	for _, j := range journeys {
+		totalWeight += j.Weight
+	}
+	if totalWeight == 0 {
+		return nil, nil
+	}
+
+	runs := make(chan journeyRun)
+	beats := make(chan heartbeat)
+	stop := make(chan struct{})
+	done := make(chan loadResult)
+	go collectLoad(rc, runs, beats, done)
+	go runHeartbeat(rc, rc.Config.LoadSnapshotInterval, beats, stop)
+
+	deadline := time.Now().Add(rc.Config.LoadDuration)
+	var wg sync.WaitGroup
+	for w := 0; w < rc.Config.LoadWorkers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(time.Now().UnixNano() + int64(w)))
+			for time.Now().Before(deadline) {
+				j := pickJourney(rnd, journeys, totalWeight)
+				start := time.Now()
+				results := runJourney(rc, j)
+				runs <- journeyRun{
+					Name:     j.Name,
+					Duration: time.Since(start),
+					Failed:   len(results) < len(j.Steps) || !results[len(results)-1].ok(),
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(runs)
+	close(stop)
+
+	result := <-done
+	return result.stats, result.timeline
+}
+
+// printLoadSummary prints per-journey results and reports whether the error
+// rate stayed within the configured limit.
+func printLoadSummary(rc *RunContext, journeys []Journey, stats map[string]*journeyStats, timeline []loadSnapshot) bool {
+	fmt.Println("\n============================================================")
+	fmt.Println("📊 LOAD TEST REPORT - ANT HILL")
+	fmt.Println("============================================================")
+	fmt.Printf("⏱️ Délka: %s, workerů: %d\n\n", rc.Config.LoadDuration, rc.Config.LoadWorkers)
+
+	runs, errors := 0, 0
+	for _, j := range journeys {
+		s := stats[j.Name]
+		if s == nil {
+			fmt.Printf("  %-18s váha %d: žádný běh\n", j.Name, j.Weight)
+			continue
+		}
+		runs += s.Runs
+		errors += s.Errors
+		fmt.Printf("  %-18s váha %d: %d běhů, %d chyb, p50 %s, p95 %s\n", j.Name, j.Weight, s.Runs, s.Errors,
+			s.Durations.percentile(0.50).Round(time.Millisecond), s.Durations.percentile(0.95).Round(time.Millisecond))
+	}
+
+	fmt.Print(renderTiming(rc.Timing.summary()))
+	fmt.Print(renderScorecard(rc.Timing.scorecard()))
+	fmt.Print(renderTimeline(timeline))
+	fmt.Print(renderResources(timeline))
+
+	errorRate := 0.0
+	if runs > 0 {
+		errorRate = float64(errors) / float64(runs)
+	}
+	fmt.Printf("\n📈 Celkem %d běhů, chybovost %.2f%% (limit %.2f%%)\n", runs, 100*errorRate, 100*rc.Config.LoadMaxErrorRate)
+	return runs > 0 && errorRate <= rc.Config.LoadMaxErrorRate
+}
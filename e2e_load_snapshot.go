@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// journeyRun is what a load worker reports after finishing one journey.
+type journeyRun struct {
+	Name     string
+	Duration time.Duration
+	Failed   bool
+}
+
+// loadSnapshot is one line of the snapshot file and one row of the timeline.
+// Interval fields cover the time since the previous snapshot, Total fields
+// the whole run so far.
+type loadSnapshot struct {
+	At          string  `json:"at"`
+	ElapsedSec  float64 `json:"elapsed_s"`
+	Health      string  `json:"health"`
+	Runs        int     `json:"runs"`
+	Errors      int     `json:"errors"`
+	P50Ms       float64 `json:"p50_ms"`
+	P95Ms       float64 `json:"p95_ms"`
+	TotalRuns   int     `json:"total_runs"`
+	TotalErrors int     `json:"total_errors"`
+	// Harness footprint at the snapshot, to tell tool limits from target ones.
+	CPUPercent float64 `json:"harness_cpu_pct"`
+	HeapMB     float64 `json:"harness_heap_mb"`
+	RSSMB      float64 `json:"harness_rss_mb,omitempty"`
+	Goroutines int     `json:"harness_goroutines"`
+	OpenFDs    int     `json:"harness_fds,omitempty"`
+	FDLimit    int     `json:"harness_fd_limit,omitempty"`
+}
+
+type heartbeat struct {
+	at     time.Time
+	health string
+}
+
+type loadResult struct {
+	stats    map[string]*journeyStats
+	timeline []loadSnapshot
+}
+
+// runHeartbeat probes backend health every interval until stop is closed.
+// Probing runs here so a slow /health never stalls the collector.
+func runHeartbeat(rc *RunContext, interval time.Duration, beats chan<- heartbeat, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			close(beats)
+			return
+		case <-ticker.C:
+			beats <- heartbeat{at: time.Now(), health: probeHealth(rc)}
+		}
+	}
+}
+
+func snapshotPath(cfg Config) string {
+	if cfg.LoadSnapshotPath != "" {
+		return cfg.LoadSnapshotPath
+	}
+	return filepath.Join(cfg.StateDir, "load-"+newRunID()+".jsonl")
+}
+
+// collectLoad is the single owner of load stats. It merges worker reports,
+// and on every heartbeat appends a snapshot to disk, so a crash late in a
+// soak run keeps everything measured up to the last heartbeat.
+func collectLoad(rc *RunContext, runs <-chan journeyRun, beats <-chan heartbeat, done chan<- loadResult) {
+	start := time.Now()
+	result := loadResult{stats: map[string]*journeyStats{}}
+
+	path := snapshotPath(rc.Config)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	file, err := os.Create(path)
+	if err != nil {
+		reportHarnessError(TelemetryReporter, err)
+		fmt.Printf("⚠️ Snapshoty nelze ukládat: %v\n", err)
+	} else {
+		defer file.Close()
+		fmt.Printf("💾 Průběžné snapshoty: %s (každých %s)\n", path, rc.Config.LoadSnapshotInterval)
+	}
+
+	var window durationHistogram
+	windowErrors, totalRuns, totalErrors := 0, 0, 0
+	health := ""
+	usage := sampleResources()
+	snapshot := func(at time.Time) {
+		prev := usage
+		usage = sampleResources()
+		snap := loadSnapshot{
+			At:          timestamp(rc.Config, at),
+			ElapsedSec:  at.Sub(start).Seconds(),
+			Health:      health,
+			Runs:        window.count,
+			Errors:      windowErrors,
+			P50Ms:       millis(window.percentile(0.50)),
+			P95Ms:       millis(window.percentile(0.95)),
+			TotalRuns:   totalRuns,
+			TotalErrors: totalErrors,
+			CPUPercent:  cpuPercent(prev, usage),
+			HeapMB:      megabytes(usage.heap),
+			RSSMB:       megabytes(usage.rss),
+			Goroutines:  usage.goroutines,
+			OpenFDs:     usage.fds,
+			FDLimit:     usage.fdLimit,
+		}
+		result.timeline = append(result.timeline, snap)
+		if file != nil {
+			line, _ := json.Marshal(snap)
+			file.Write(append(line, '\n'))
+		}
+		window, windowErrors = durationHistogram{}, 0
+	}
+
+	for runs != nil || beats != nil {
+		select {
+		case run, ok := <-runs:
+			if !ok {
+				runs = nil
+				continue
+			}
+			s := result.stats[run.Name]
+			if s == nil {
+				s = &journeyStats{}
+				result.stats[run.Name] = s
+			}
+			s.Runs++
+			s.Durations.add(run.Duration)
+			window.add(run.Duration)
+			totalRuns++
+			if run.Failed {
+				s.Errors++
+				windowErrors++
+				totalErrors++
+			}
+		case beat, ok := <-beats:
+			if !ok {
+				beats = nil
+				continue
+			}
+			health = beat.health
+			snapshot(beat.at)
+		}
+	}
+	if window.count > 0 {
+		// The tail after the last heartbeat has no health probe of its own.
+		health = ""
+		snapshot(time.Now())
+	}
+	done <- result
+}
+
+// renderTimeline formats the snapshots and the heartbeat uptime.
+func renderTimeline(timeline []loadSnapshot) string {
+	if len(timeline) == 0 {
+		return ""
+	}
+	out := "\n🕒 TIMELINE:\n"
+	beats, up := 0, 0
+	for _, s := range timeline {
+		rate := 0.0
+		if s.Runs > 0 {
+			rate = 100 * float64(s.Errors) / float64(s.Runs)
+		}
+		health := s.Health
+		if health == "" {
+			health = "-"
+		} else {
+			beats++
+			if health == "ok" || health == "degraded" {
+				up++
+			}
+		}
+		if r := []rune(health); len(r) > 20 {
+			health = string(r[:20]) + "…"
+		}
+		elapsed := time.Duration(s.ElapsedSec * float64(time.Second)).Round(time.Second)
+		out += fmt.Sprintf("  +%-8s %-21s %5d běhů, %4d chyb (%.2f%%), p50 %.0fms, p95 %.0fms, harness cpu %.0f%% %d gorutin\n",
+			elapsed, health, s.Runs, s.Errors, rate, s.P50Ms, s.P95Ms, s.CPUPercent, s.Goroutines)
+	}
+	if beats > 0 {
+		out += fmt.Sprintf("\n💓 Uptime backendu: %d/%d heartbeatů OK (%.1f%%)\n", up, beats, 100*float64(up)/float64(beats))
+		if down := beats - up; down > 0 {
+			out += fmt.Sprintf("   Výpadky: %s\n", strings.Join(downBeats(timeline), ", "))
+		}
+	}
+	return out
+}
+
+func downBeats(timeline []loadSnapshot) []string {
+	var down []string
+	for _, s := range timeline {
+		if s.Health != "" && s.Health != "ok" && s.Health != "degraded" {
+			down = append(down, "+"+time.Duration(s.ElapsedSec*float64(time.Second)).Round(time.Second).String())
+		}
+	}
+	return down
+}
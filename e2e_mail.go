@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// mailPollTimeout is how long an email may take to reach the mail-catcher.
+const mailPollTimeout = 20 * time.Second
+
+var mailLinkRe = regexp.MustCompile(`https?://[^\s"'<>)]+`)
+
+// mailMessage is the catcher-independent view of one caught email.
+type mailMessage struct {
+	ID      string
+	Subject string
+	To      []string
+	Body    string
+	Created time.Time
+}
+
+// mailEvent is an app event that sends an email to -mail-to. trigger causes
+// the event and returns a cleanup function.
+type mailEvent struct {
+	name     string
+	subjects []string
+	trigger  func(rc *RunContext) (func(), error)
+}
+
+var mailEvents = []mailEvent{
+	{"task approved", []string{"approved", "schválen"}, triggerTaskApproved},
+	{"new follower", []string{"follower", "sleduje"}, triggerNewFollower},
+}
+
+func triggerTaskApproved(rc *RunContext) (func(), error) {
+	id, err := createTaskWith(rc, map[string]string{"title": "E2E approval mail", "owner_email": rc.Config.MailTo})
+	if err != nil {
+		return nil, err
+	}
+	cleanup := func() { deleteTask(rc, id) }
+	_, err = postJSON(rc, fmt.Sprintf("/api/tasks/%d/approve", id), nil, nil)
+	return cleanup, err
+}
+
+func triggerNewFollower(rc *RunContext) (func(), error) {
+	_, err := postJSON(rc, "/api/users/follow", map[string]string{"email": rc.Config.MailTo}, nil)
+	return func() {}, err
+}
+
+// fetchMail lists caught messages, using the Mailpit API when available and
+// the MailHog API otherwise.
+func fetchMail(rc *RunContext) ([]mailMessage, error) {
+	base := strings.TrimSuffix(rc.Config.MailCatcherURL, "/")
+	var mailpit struct {
+		Messages []struct {
+			ID      string    `json:"ID"`
+			Subject string    `json:"Subject"`
+			Created time.Time `json:"Created"`
+			To      []struct {
+				Address string `json:"Address"`
+			} `json:"To"`
+		} `json:"messages"`
+	}
+	if _, err := fetchJSON(rc, base+"/api/v1/messages", &mailpit); err == nil {
+		var messages []mailMessage
+		for _, m := range mailpit.Messages {
+			msg := mailMessage{ID: m.ID, Subject: m.Subject, Created: m.Created}
+			for _, to := range m.To {
+				msg.To = append(msg.To, to.Address)
+			}
+			messages = append(messages, msg)
+		}
+		return messages, nil
+	}
+
+	var mailhog struct {
+		Items []struct {
+			ID      string    `json:"ID"`
+			Created time.Time `json:"Created"`
+			Content struct {
+				Headers map[string][]string `json:"Headers"`
+				Body    string              `json:"Body"`
+			} `json:"Content"`
+		} `json:"items"`
+	}
+	if _, err := fetchJSON(rc, base+"/api/v2/messages", &mailhog); err != nil {
+		return nil, fmt.Errorf("mail-catcher (Mailpit ani MailHog API): %w", err)
+	}
+	var messages []mailMessage
+	for _, m := range mailhog.Items {
+		msg := mailMessage{ID: m.ID, Body: m.Content.Body, Created: m.Created, To: m.Content.Headers["To"]}
+		if subject := m.Content.Headers["Subject"]; len(subject) > 0 {
+			msg.Subject = subject[0]
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// mailBody returns the text and HTML of a Mailpit message. MailHog lists
+// include the body already.
+func mailBody(rc *RunContext, msg mailMessage) (string, error) {
+	if msg.Body != "" {
+		return msg.Body, nil
+	}
+	var full struct {
+		Text string `json:"Text"`
+		HTML string `json:"HTML"`
+	}
+	if _, err := fetchJSON(rc, strings.TrimSuffix(rc.Config.MailCatcherURL, "/")+"/api/v1/message/"+url.PathEscape(msg.ID), &full); err != nil {
+		return "", err
+	}
+	return full.Text + "\n" + full.HTML, nil
+}
+
+func (m mailMessage) sentTo(address string) bool {
+	for _, to := range m.To {
+		if strings.Contains(strings.ToLower(to), strings.ToLower(address)) {
+			return true
+		}
+	}
+	return false
+}
+
+func subjectMatches(subject string, keywords []string) bool {
+	for _, k := range keywords {
+		if strings.Contains(strings.ToLower(subject), k) {
+			return true
+		}
+	}
+	return false
+}
+
+// awaitMail polls the catcher for a message to -mail-to with a matching
+// subject, caught after since.
+func awaitMail(rc *RunContext, event mailEvent, since time.Time) (mailMessage, error) {
+	deadline := time.Now().Add(mailPollTimeout)
+	for {
+		messages, err := fetchMail(rc)
+		if err != nil {
+			return mailMessage{}, err
+		}
+		for _, m := range messages {
+			// Allow for clock differences between the harness and the catcher.
+			if m.Created.Before(since.Add(-5*time.Second)) || !m.sentTo(rc.Config.MailTo) {
+				continue
+			}
+			if subjectMatches(m.Subject, event.subjects) {
+				return m, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return mailMessage{}, failf(ReasonTimeout, "Email %q pro %s nedorazil do %s", event.name, rc.Config.MailTo, mailPollTimeout)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// foreignLinks returns links in body that point elsewhere than the frontend
+// or backend under test, e.g. at localhost from a misconfigured base URL.
+func foreignLinks(rc *RunContext, body string) []string {
+	var foreign []string
+	for _, loc := range mailLinkRe.FindAllStringIndex(body, -1) {
+		// XML namespaces in HTML mails are not links.
+		if strings.Contains(body[max(0, loc[0]-16):loc[0]], "xmlns") {
+			continue
+		}
+		link := strings.TrimRight(body[loc[0]:loc[1]], ".,;")
+		if !sameOrigin(link, rc.Config.FrontendURL) && !sameOrigin(link, rc.Config.BackendURL) {
+			foreign = append(foreign, link)
+		}
+	}
+	return foreign
+}
+
+// testEmailNotifications triggers every email-sending event and validates the
+// caught email: recipient, subject and links.
+func testEmailNotifications(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n📧 TEST: Email Notifications")
+
+	var errs []error
+	for _, event := range mailEvents {
+		since := time.Now()
+		cleanup, err := event.trigger(rc)
+		if cleanup != nil {
+			defer cleanup()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Událost %s - %w", event.name, err))
+			continue
+		}
+
+		msg, err := awaitMail(rc, event, since)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		body, err := mailBody(rc, msg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Email %s - %w", event.name, err))
+			continue
+		}
+		if foreign := foreignLinks(rc, body); len(foreign) > 0 {
+			errs = append(errs, failf(ReasonAssertion, "Email %s odkazuje mimo testované prostředí: %s", event.name, strings.Join(foreign, ", ")))
+			continue
+		}
+		fmt.Fprintf(rc.Out, "✅ %s: %q pro %s\n", event.name, msg.Subject, rc.Config.MailTo)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// testMatrix maps a test name to parameters and their values from the
+// "matrix" section of the -config file. The test runs once for every
+// combination and each instance is reported on its own.
+type testMatrix map[string]map[string][]string
+
+// parameterizedTests are the tests that apply rc.Params, through paramURL.
+// A matrix for any other test would only repeat identical runs.
+var parameterizedTests = map[string]bool{
+	"Marketplace API": true,
+	"Leaderboard API": true,
+}
+
+// matrixInstances returns the cartesian product of params in a stable order.
+func matrixInstances(params map[string][]string) []map[string]string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	instances := []map[string]string{{}}
+	for _, key := range keys {
+		var next []map[string]string
+		for _, instance := range instances {
+			for _, value := range params[key] {
+				combined := map[string]string{key: value}
+				for k, v := range instance {
+					combined[k] = v
+				}
+				next = append(next, combined)
+			}
+		}
+		instances = next
+	}
+	return instances
+}
+
+func instanceName(name string, params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + params[key]
+	}
+	return fmt.Sprintf("%s [%s]", name, strings.Join(pairs, ", "))
+}
+
+// baseTestName strips the parameters from the name of a matrix instance.
+func baseTestName(name string) string {
+	base, _, _ := strings.Cut(name, " [")
+	return base
+}
+
+// expandMatrix replaces every test with a matrix entry by its instances.
+func expandMatrix(tests []testCase, matrix testMatrix) []testCase {
+	registered := map[string]bool{}
+	var expanded []testCase
+	for _, test := range tests {
+		registered[test.name] = true
+		params, ok := matrix[test.name]
+		if !ok {
+			expanded = append(expanded, test)
+			continue
+		}
+		for _, instance := range matrixInstances(params) {
+			fn := test.fn
+			expanded = append(expanded, testCase{instanceName(test.name, instance), func(rc *RunContext) error {
+				rc.Params = instance
+				return fn(rc)
+			}, test.smoke, test.severity, test.service, test.suite})
+		}
+	}
+	for name := range matrix {
+		if !registered[name] {
+			fmt.Printf("⚠️ Matice: test %q v tomto běhu neběží\n", name)
+		}
+	}
+	return expanded
+}
+
+func validateMatrix(matrix testMatrix) error {
+	for name, params := range matrix {
+		if !parameterizedTests[name] {
+			var accepted []string
+			for test := range parameterizedTests {
+				accepted = append(accepted, test)
+			}
+			sort.Strings(accepted)
+			return fmt.Errorf("matice %q: test nepřijímá parametry (jen %s)", name, strings.Join(accepted, ", "))
+		}
+		for key, values := range params {
+			if len(values) == 0 {
+				return fmt.Errorf("matice %q: parametr %s nemá žádnou hodnotu", name, key)
+			}
+		}
+	}
+	return nil
+}
+
+// paramURL adds the parameters of a matrix instance to target as query
+// parameters.
+func (rc *RunContext) paramURL(target string) string {
+	if len(rc.Params) == 0 {
+		return target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	q := u.Query()
+	for key, value := range rc.Params {
+		q.Set(key, value)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
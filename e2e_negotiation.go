@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// negotiatedTypes are the alternative formats partners request from listing
+// endpoints. Each must be served as asked or refused with a clean 406.
+var negotiatedTypes = []string{"text/csv", "application/xml"}
+
+// checkCSV parses a CSV listing; every row must have as many fields as the
+// header.
+func checkCSV(body []byte) (int, error) {
+	rows, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 || strings.Join(rows[0], "") == "" {
+		return 0, fmt.Errorf("chybí hlavička")
+	}
+	return len(rows) - 1, nil
+}
+
+// checkXML checks that an XML listing is well-formed with a single root and
+// returns the number of its child elements.
+func checkXML(body []byte) (int, error) {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	depth, roots, items := 0, 0, 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+			} else if depth == 1 {
+				items++
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	if roots != 1 {
+		return 0, fmt.Errorf("očekáván jeden kořenový element, nalezeno %d", roots)
+	}
+	return items, nil
+}
+
+// negotiate requests target with Accept: accept and checks that the answer is
+// either that format or a 406 without an HTML error page.
+func negotiate(rc *RunContext, name, target, accept string) error {
+	req, _ := http.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Accept", accept)
+	resp, err := rc.Client.Do(req)
+	if err != nil {
+		return requestFailure(fmt.Sprintf("%s (%s)", name, accept), err)
+	}
+	defer resp.Body.Close()
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return requestFailure(fmt.Sprintf("%s (%s)", name, accept), err)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotAcceptable:
+		if mediaType == "text/html" {
+			return failf(ReasonAssertion, "%s (%s): 406 vrací HTML stránku místo chybové odpovědi API", name, accept)
+		}
+		fmt.Fprintf(rc.Out, "   %s %s: 406 Not Acceptable\n", name, accept)
+		return nil
+	default:
+		return failf(ReasonBadStatus, "%s (%s): status %d, očekáváno 200 nebo 406", name, accept, resp.StatusCode)
+	}
+
+	// Ignoring Accept and answering JSON breaks partners parsing the format.
+	if mediaType != accept {
+		return failf(ReasonAssertion, "%s (%s): odpověď 200 s Content-Type %q, očekáváno %s nebo 406", name, accept, mediaType, accept)
+	}
+	var items int
+	if accept == "text/csv" {
+		items, err = checkCSV(body)
+	} else {
+		items, err = checkXML(body)
+	}
+	if err != nil {
+		return failf(ReasonSchema, "%s (%s): neplatný obsah: %v", name, accept, err)
+	}
+	if !varies(resp.Header.Get("Vary"), "Accept") {
+		fmt.Fprintf(rc.Out, "   ⚠️ %s %s: chybí Vary: Accept, cache může vrátit jiný formát\n", name, accept)
+	}
+	fmt.Fprintf(rc.Out, "   %s %s: %d záznamů\n", name, accept, items)
+	return nil
+}
+
+// testContentNegotiation requests every resolved listing endpoint as CSV and
+// XML.
+func testContentNegotiation(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🧾 TEST: Content Negotiation")
+
+	var errs []error
+	var unresolved error
+	checked := 0
+	for _, name := range []string{EndpointMarketplace, EndpointLeaderboard, EndpointNotifications} {
+		target, err := rc.endpoint(name)
+		if err != nil {
+			fmt.Fprintf(rc.Out, "   ⏭️ %s: %v\n", name, err)
+			if unresolved == nil {
+				unresolved = err
+			}
+			continue
+		}
+		for _, accept := range negotiatedTypes {
+			checked++
+			if err := negotiate(rc, name, target, accept); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if checked == 0 {
+		return fmt.Errorf("Žádný listing endpoint nebyl rozpoznán: %w", unresolved)
+	}
+	fmt.Fprintf(rc.Out, "✅ Content negotiation v pořádku (%d kombinací)\n", checked)
+	return nil
+}
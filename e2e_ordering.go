@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	orderingWrites   = 20
+	orderingInterval = 50 * time.Millisecond
+)
+
+// orderingViolation compares two consecutive task lists. Tasks present in
+// both must keep their relative order and no list may repeat a task.
+func orderingViolation(prev, cur []int) string {
+	seen := map[int]bool{}
+	for _, id := range cur {
+		if seen[id] {
+			return fmt.Sprintf("task %d je v seznamu dvakrát", id)
+		}
+		seen[id] = true
+	}
+	// prev may be a read already flagged for a duplicate; only its first
+	// occurrence of a task counts.
+	inPrev := map[int]bool{}
+	var a, b []int
+	for _, id := range prev {
+		if !inPrev[id] && seen[id] {
+			a = append(a, id)
+		}
+		inPrev[id] = true
+	}
+	for _, id := range cur {
+		if inPrev[id] {
+			b = append(b, id)
+		}
+	}
+	for i := range min(len(a), len(b)) {
+		if a[i] != b[i] {
+			return fmt.Sprintf("na pozici %d byl task %d, nyní %d", i, a[i], b[i])
+		}
+	}
+	return ""
+}
+
+// testOrderingUnderWrites creates tasks in the background while repeatedly
+// listing tasks, and checks that the list order stays stable and no request
+// fails with a 5xx.
+func testOrderingUnderWrites(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🔀 TEST: Ordering Under Writes")
+
+	marker := "E2E ordering " + newRunID()
+	var (
+		mu         sync.Mutex
+		created    []int
+		writeErr   error
+		writeFails []string
+	)
+	done := make(chan struct{})
+	defer func() {
+		<-done
+		for _, id := range created {
+			deleteTask(rc, id)
+		}
+	}()
+
+	go func() {
+		defer close(done)
+		for i := 1; i <= orderingWrites; i++ {
+			var task struct {
+				ID int `json:"id"`
+			}
+			status, err := postJSON(rc, "/api/tasks", map[string]string{"title": fmt.Sprintf("%s #%d", marker, i)}, &task)
+			mu.Lock()
+			switch {
+			case status >= http.StatusInternalServerError:
+				writeFails = append(writeFails, fmt.Sprintf("zápis %d: status %d", i, status))
+			case err != nil && writeErr == nil:
+				writeErr = fmt.Errorf("Zápis %d - %w", i, err)
+			case err == nil:
+				created = append(created, task.ID)
+			}
+			mu.Unlock()
+			time.Sleep(orderingInterval)
+		}
+	}()
+
+	var (
+		readErr   error
+		prev      []int
+		reads     int
+		serverErr []string
+		reordered []string
+	)
+	for running := true; running && readErr == nil; {
+		select {
+		case <-done:
+			// One more read sees the final list.
+			running = false
+		case <-time.After(orderingInterval / 5):
+		}
+		var tasks []struct {
+			ID int `json:"id"`
+		}
+		status, err := fetchJSON(rc, rc.Config.BackendURL+"/api/tasks", &tasks)
+		reads++
+		switch {
+		case status >= http.StatusInternalServerError:
+			serverErr = append(serverErr, fmt.Sprintf("čtení %d: status %d", reads, status))
+			continue
+		case err != nil:
+			readErr = fmt.Errorf("Čtení %d - %w", reads, err)
+			continue
+		}
+		cur := make([]int, len(tasks))
+		for i, t := range tasks {
+			cur[i] = t.ID
+		}
+		if prev != nil {
+			if v := orderingViolation(prev, cur); v != "" {
+				reordered = append(reordered, fmt.Sprintf("čtení %d: %s", reads, v))
+			}
+		}
+		prev = cur
+	}
+	<-done
+
+	var errs []error
+	for _, err := range []error{readErr, writeErr} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	serverErr = append(serverErr, writeFails...)
+	fmt.Fprintf(rc.Out, "   %d čtení, %d/%d zápisů, %d změn pořadí, %d chyb 5xx\n", reads, len(created), orderingWrites, len(reordered), len(serverErr))
+	if len(serverErr) > 0 {
+		errs = append(errs, failf(ReasonBadStatus, "%d chyb 5xx při souběžném zápisu, první: %s", len(serverErr), serverErr[0]))
+	}
+	if len(reordered) > 0 {
+		errs = append(errs, failf(ReasonAssertion, "Pořadí tasků se změnilo %dx při souběžném zápisu, první: %s", len(reordered), reordered[0]))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	fmt.Fprintf(rc.Out, "✅ Pořadí stabilní během %d zápisů\n", len(created))
+	return nil
+}
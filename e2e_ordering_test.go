@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestOrderingViolation(t *testing.T) {
+	cases := []struct {
+		name      string
+		prev, cur []int
+		violation bool
+	}{
+		{"stable", []int{1, 2, 3}, []int{1, 2, 3, 4}, false},
+		{"reordered", []int{1, 2, 3}, []int{2, 1, 3}, true},
+		{"duplicate in cur", []int{1, 2}, []int{1, 2, 2}, true},
+		{"duplicate in prev", []int{1, 2, 2}, []int{1, 2}, false},
+		{"duplicate in prev, reordered", []int{1, 2, 2}, []int{2, 1}, true},
+	}
+	for _, c := range cases {
+		if got := orderingViolation(c.prev, c.cur); (got != "") != c.violation {
+			t.Errorf("%s: orderingViolation(%v, %v) = %q", c.name, c.prev, c.cur, got)
+		}
+	}
+}
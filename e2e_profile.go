@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Profile is one environment of the -config file. Its URLs are used unless
+// the matching flag is given explicitly.
+type Profile struct {
+	Backend  string     `json:"backend"`
+	Frontend string     `json:"frontend"`
+	Auth     AuthConfig `json:"auth"`
+	// Roles are extra identities for role-specific suites, e.g. "admin".
+	Roles map[string]AuthConfig `json:"roles"`
+	// Services are base URLs of backend services split out of Backend.
+	Services map[string]string `json:"services"`
+}
+
+type profileFile struct {
+	Environments map[string]Profile `json:"environments"`
+	// Matrix is shared by all environments.
+	Matrix testMatrix `json:"matrix"`
+}
+
+// loadProfile reads environment env from path and applies it to cfg.
+func loadProfile(cfg *Config, fs *flag.FlagSet, path, env string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var file profileFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	profile, ok := file.Environments[env]
+	if !ok {
+		return fmt.Errorf("%s: prostředí %q neexistuje", path, env)
+	}
+	if err := validateMatrix(file.Matrix); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.Matrix = file.Matrix
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if profile.Backend != "" && !set["backend"] {
+		cfg.BackendURL = profile.Backend
+	}
+	if profile.Frontend != "" && !set["frontend"] {
+		cfg.FrontendURL = profile.Frontend
+	}
+	// A -service flag wins over the profile entry of the same service.
+	for name, url := range profile.Services {
+		if _, ok := cfg.Services[name]; !ok {
+			cfg.Services[name] = strings.TrimSuffix(url, "/")
+		}
+	}
+
+	if cfg.Auth, err = resolveAuth(env, profile.Auth); err != nil {
+		return err
+	}
+	cfg.Roles = map[string]AuthConfig{}
+	for role, auth := range profile.Roles {
+		if cfg.Roles[role], err = resolveAuth(env+"/"+role, auth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveAuth validates an auth section and reads its secret from the
+// environment.
+func resolveAuth(env string, auth AuthConfig) (AuthConfig, error) {
+	var err error
+	switch auth.Type {
+	case "", "none":
+	case "api-key":
+		auth.Key, err = secretFromEnv(auth.KeyEnv)
+	case "bearer":
+		auth.Token, err = secretFromEnv(auth.TokenEnv)
+	case "basic":
+		auth.Password, err = secretFromEnv(auth.PasswordEnv)
+	case "session":
+		if auth.LoginPath == "" {
+			return auth, fmt.Errorf("prostředí %s: session auth vyžaduje login_path", env)
+		}
+		auth.Password, err = secretFromEnv(auth.PasswordEnv)
+	default:
+		return auth, fmt.Errorf("prostředí %s: neznámý typ auth %q (none, api-key, basic, bearer, session)", env, auth.Type)
+	}
+	if err != nil {
+		return auth, fmt.Errorf("prostředí %s: %w", env, err)
+	}
+	return auth, nil
+}
+
+func secretFromEnv(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("chybí název proměnné prostředí se secretem")
+	}
+	value := os.Getenv(name)
+	if value == "" {
+		return "", fmt.Errorf("proměnná prostředí %s není nastavena", name)
+	}
+	return value, nil
+}
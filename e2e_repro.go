@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// maxReproBody caps the request body kept for a reproduction script.
+const maxReproBody = 64 << 10
+
+// secretHeaders are never written to a reproduction script verbatim.
+var secretHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "X-Api-Key"}
+
+type recordedRequest struct {
+	role      string
+	method    string
+	url       string
+	header    http.Header
+	body      []byte
+	truncated bool
+	status    int
+	err       error
+}
+
+// reproRecorder keeps the requests of one test attempt in the order they
+// were sent.
+type reproRecorder struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+func (r *reproRecorder) add(req recordedRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+}
+
+// recordTransport records requests above the auth transport, so the
+// recorded headers never contain the injected credentials.
+type recordTransport struct {
+	next http.RoundTripper
+	rec  *reproRecorder
+	role string
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rr := recordedRequest{role: t.role, method: req.Method, url: req.URL.String(), header: req.Header.Clone()}
+	if req.Body != nil && req.Body != http.NoBody {
+		body := req.Body
+		if req.GetBody != nil {
+			if replay, err := req.GetBody(); err == nil {
+				body = replay
+			}
+		}
+		data, _ := io.ReadAll(io.LimitReader(body, maxReproBody+1))
+		if body == req.Body {
+			// The body could not be replayed, so it is handed on from the copy.
+			req.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(data), req.Body), req.Body}
+		} else {
+			body.Close()
+		}
+		rr.body, rr.truncated = data[:min(len(data), maxReproBody)], len(data) > maxReproBody
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		rr.err = err
+	} else {
+		rr.status = resp.StatusCode
+	}
+	t.rec.add(rr)
+	return resp, err
+}
+
+// recordingContext returns a copy of rc whose clients record into rec.
+func recordingContext(rc *RunContext, rec *reproRecorder) RunContext {
+	arc := *rc
+	wrap := func(client *http.Client, role string) *http.Client {
+		c := *client
+		c.Transport = &recordTransport{next: client.Transport, rec: rec, role: role}
+		return &c
+	}
+	arc.Client = wrap(rc.Client, "")
+	arc.Roles = map[string]*http.Client{}
+	for role, client := range rc.Roles {
+		arc.Roles[role] = wrap(client, role)
+	}
+	return arc
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func envName(s string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, s))
+}
+
+// authArray defines the bash array with the curl arguments of one identity.
+// Secrets are read from the same environment variables the profile uses.
+func authArray(name string, auth AuthConfig) string {
+	var args string
+	switch auth.Type {
+	case "api-key":
+		header := auth.Header
+		if header == "" {
+			header = "X-API-Key"
+		}
+		args = fmt.Sprintf(`-H "%s: ${%s:?}"`, header, auth.KeyEnv)
+	case "bearer":
+		args = fmt.Sprintf(`-H "Authorization: Bearer ${%s:?}"`, auth.TokenEnv)
+	case "basic":
+		args = fmt.Sprintf(`-u "%s:${%s:?}"`, auth.Username, auth.PasswordEnv)
+	case "session":
+		// The login flow is not replayed; the cookie comes from a manual login.
+		args = fmt.Sprintf(`-H "Cookie: ${E2E_SESSION_COOKIE_%s:?přihlaste se přes %s a nastavte session cookie}"`, envName(name), auth.LoginPath)
+	}
+	return fmt.Sprintf("%s=(%s)\n", name, args)
+}
+
+func authVar(role string) string {
+	if role == "" {
+		return "AUTH"
+	}
+	return "AUTH_" + envName(role)
+}
+
+// renderRepro writes the recorded requests of a failed test as a bash script
+// of curl calls that backend developers can replay.
+func renderRepro(cfg Config, test string, failure error, requests []recordedRequest) string {
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	fmt.Fprintf(&b, "# Reprodukce selhání testu %q, vygenerováno %s\n", test, time.Now().In(cfg.Location).Format(time.RFC3339))
+	for _, line := range strings.Split(failure.Error(), "\n") {
+		fmt.Fprintf(&b, "# [%s] %s\n", reasonOf(failure), line)
+	}
+	b.WriteString("# Secrety skript neobsahuje, čte je z proměnných prostředí.\n")
+	b.WriteString("set -u\n\n")
+
+	b.WriteString(authArray(authVar(""), cfg.Auth))
+	roles := make([]string, 0, len(cfg.Roles))
+	for role := range cfg.Roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		b.WriteString(authArray(authVar(role), cfg.Roles[role]))
+	}
+
+	for i, r := range requests {
+		result := fmt.Sprintf("status %d", r.status)
+		if r.err != nil {
+			result = "chyba: " + strings.ReplaceAll(r.err.Error(), "\n", " ")
+		}
+		fmt.Fprintf(&b, "\n# %d/%d %s %s → %s\n", i+1, len(requests), r.method, r.url, result)
+		if r.truncated {
+			fmt.Fprintf(&b, "# Tělo zkráceno na %d B.\n", maxReproBody)
+		}
+
+		args := []string{"curl", "-sS", "-i", "-X", r.method, shellQuote(r.url)}
+		if matchesBase(r.url, cfg.backendURLs()) {
+			args = append(args, fmt.Sprintf(`"${%s[@]}"`, authVar(r.role)))
+		}
+		names := make([]string, 0, len(r.header))
+		for name := range r.header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if name == "Content-Length" {
+				continue
+			}
+			for _, value := range r.header[name] {
+				if isSecretHeader(cfg, name) {
+					args = append(args, "-H", fmt.Sprintf(`"%s: ${E2E_%s:?}"`, name, envName(name)))
+				} else {
+					args = append(args, "-H", shellQuote(name+": "+value))
+				}
+			}
+		}
+
+		switch {
+		case r.body == nil:
+		case utf8.Valid(r.body) && !bytes.ContainsRune(r.body, 0):
+			fmt.Fprintf(&b, "printf '%%s' %s |\n  ", shellQuote(string(r.body)))
+			args = append(args, "--data-binary", "@-")
+		default:
+			fmt.Fprintf(&b, "base64 -d <<'E2E_BODY' |\n%s\nE2E_BODY\n  ", wrapBase64(r.body))
+			args = append(args, "--data-binary", "@-")
+		}
+		b.WriteString(strings.Join(args, " "))
+		b.WriteString("\necho\n")
+	}
+	return b.String()
+}
+
+func isSecretHeader(cfg Config, name string) bool {
+	for _, h := range secretHeaders {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return cfg.Auth.Header != "" && strings.EqualFold(cfg.Auth.Header, name)
+}
+
+func wrapBase64(data []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var lines []string
+	for len(encoded) > 76 {
+		lines = append(lines, encoded[:76])
+		encoded = encoded[76:]
+	}
+	return strings.Join(append(lines, encoded), "\n")
+}
+
+// reproDir is the directory next to the text report holding the scripts.
+func reproDir(cfg Config) string {
+	return strings.TrimSuffix(cfg.ReportPath, filepath.Ext(cfg.ReportPath)) + "_repro"
+}
+
+// writeRepro saves the reproduction script of a failed test and returns its
+// path.
+func writeRepro(cfg Config, test string, failure error, requests []recordedRequest) (string, error) {
+	dir := reproDir(cfg)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := strings.Trim(strings.ToLower(envName(test)), "_")
+	path := filepath.Join(dir, strings.ReplaceAll(name, "_", "-")+".sh")
+	return path, os.WriteFile(path, []byte(renderRepro(cfg, test, failure, requests)), 0755)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// resourceUsage is the harness's own footprint at one moment. Process
+// counters come from the OS; fields it does not expose stay zero.
+type resourceUsage struct {
+	at         time.Time
+	cpu        time.Duration
+	heap       uint64
+	rss        uint64
+	goroutines int
+	fds        int
+	fdLimit    int
+}
+
+func sampleResources() resourceUsage {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	u := resourceUsage{at: time.Now(), heap: m.HeapAlloc, goroutines: runtime.NumGoroutine()}
+	u.cpu = processCPU()
+	u.rss = residentMemory()
+	u.fds, u.fdLimit = openFiles()
+	return u
+}
+
+// residentMemory reads the current RSS on Linux; other systems only report
+// the peak through getrusage, which is not comparable, so it stays zero.
+func residentMemory() uint64 {
+	statm, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(statm))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, _ := strconv.ParseUint(fields[1], 10, 64)
+	return pages * uint64(os.Getpagesize())
+}
+
+// cpuPercent is the CPU the harness used between two samples, 100% being
+// one fully busy core.
+func cpuPercent(prev, cur resourceUsage) float64 {
+	wall := cur.at.Sub(prev.at)
+	if wall <= 0 {
+		return 0
+	}
+	return 100 * float64(cur.cpu-prev.cpu) / float64(wall)
+}
+
+func megabytes(b uint64) float64 {
+	return float64(b) / (1 << 20)
+}
+
+// renderResources summarizes the harness footprint over a load run and flags
+// when the tool itself, not the target, is the likely bottleneck.
+func renderResources(timeline []loadSnapshot) string {
+	if len(timeline) == 0 {
+		return ""
+	}
+	var peak loadSnapshot
+	for _, s := range timeline {
+		peak.CPUPercent = max(peak.CPUPercent, s.CPUPercent)
+		peak.HeapMB = max(peak.HeapMB, s.HeapMB)
+		peak.RSSMB = max(peak.RSSMB, s.RSSMB)
+		peak.Goroutines = max(peak.Goroutines, s.Goroutines)
+		peak.OpenFDs = max(peak.OpenFDs, s.OpenFDs)
+		peak.FDLimit = max(peak.FDLimit, s.FDLimit)
+	}
+
+	out := "\n🧰 ZDROJE HARNESSU (maximum během běhu):\n"
+	cores := runtime.NumCPU()
+	out += fmt.Sprintf("  CPU: %.0f%% z %d%% (počet CPU: %d), gorutiny: %d\n", peak.CPUPercent, 100*cores, cores, peak.Goroutines)
+	out += fmt.Sprintf("  Heap: %.1f MB", peak.HeapMB)
+	if peak.RSSMB > 0 {
+		out += fmt.Sprintf(", RSS: %.1f MB", peak.RSSMB)
+	}
+	out += fmt.Sprintf("\n  Otevřené soubory: %d (limit %d)\n", peak.OpenFDs, peak.FDLimit)
+	if peak.CPUPercent >= 0.8*float64(100*cores) {
+		out += "  ⚠️ Harness vytěžuje CPU, latence může omezovat nástroj, ne cílový systém\n"
+	}
+	if peak.FDLimit > 0 && peak.OpenFDs >= peak.FDLimit*8/10 {
+		out += "  ⚠️ Harness se blíží limitu otevřených souborů, zvyšte ulimit -n nebo snižte -load-workers\n"
+	}
+	return out
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// processCPU is the user and system CPU time the harness used so far.
+func processCPU() time.Duration {
+	var ru syscall.Rusage
+	if syscall.Getrusage(syscall.RUSAGE_SELF, &ru) != nil {
+		return 0
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
+
+// openFiles returns the open descriptors and their soft limit.
+func openFiles() (int, int) {
+	fds, limit := 0, 0
+	if entries, err := os.ReadDir("/dev/fd"); err == nil {
+		// One descriptor is the listing of the directory itself.
+		fds = len(entries) - 1
+	}
+	var rlimit syscall.Rlimit
+	if syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit) == nil {
+		limit = int(rlimit.Cur)
+	}
+	return fds, limit
+}
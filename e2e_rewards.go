@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// rewardNotificationTimeout is how long the redemption confirmation may take.
+const rewardNotificationTimeout = 10 * time.Second
+
+type reward struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Cost    int    `json:"cost"`
+	Sandbox bool   `json:"sandbox"`
+}
+
+type rewardOrder struct {
+	ID       int    `json:"id"`
+	RewardID int    `json:"reward_id"`
+	Status   string `json:"status"`
+}
+
+func pointsBalance(rc *RunContext) (int, error) {
+	var balance struct {
+		Points *int `json:"points"`
+	}
+	if _, err := fetchJSON(rc, rc.Config.BackendURL+"/api/rewards/balance", &balance); err != nil {
+		return 0, err
+	}
+	if balance.Points == nil {
+		return 0, failf(ReasonSchema, "GET /api/rewards/balance: chybí points")
+	}
+	return *balance.Points, nil
+}
+
+// testRewardRedemption redeems points for a sandbox reward and checks the
+// balance, the created order and the confirmation notification.
+func testRewardRedemption(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🎁 TEST: Reward Redemption")
+
+	var catalog []reward
+	if _, err := fetchJSON(rc, rc.Config.BackendURL+"/api/rewards", &catalog); err != nil {
+		return fmt.Errorf("Katalog odměn - %w", err)
+	}
+	var item *reward
+	for i := range catalog {
+		if catalog[i].Sandbox {
+			item = &catalog[i]
+			break
+		}
+	}
+	if item == nil {
+		return failf(ReasonAssertion, "Katalog neobsahuje sandbox odměnu (%d odměn)", len(catalog))
+	}
+
+	before, err := pointsBalance(rc)
+	if err != nil {
+		return fmt.Errorf("Zůstatek bodů - %w", err)
+	}
+	if before < item.Cost {
+		return failf(ReasonAssertion, "Testovací účet má %d bodů, odměna %s stojí %d", before, item.Name, item.Cost)
+	}
+
+	var order struct {
+		OrderID int `json:"order_id"`
+	}
+	if _, err := postJSON(rc, fmt.Sprintf("/api/rewards/%d/redeem", item.ID), nil, &order); err != nil {
+		return fmt.Errorf("Uplatnění odměny - %w", err)
+	}
+	if order.OrderID == 0 {
+		return failf(ReasonSchema, "Uplatnění odměny nevrátilo order_id")
+	}
+	fmt.Fprintf(rc.Out, "   Odměna %s (%d bodů) → objednávka %d\n", item.Name, item.Cost, order.OrderID)
+
+	var errs []string
+	after, err := pointsBalance(rc)
+	switch {
+	case err != nil:
+		return fmt.Errorf("Zůstatek bodů po uplatnění - %w", err)
+	case after != before-item.Cost:
+		errs = append(errs, fmt.Sprintf("zůstatek %d → %d, očekáváno %d", before, after, before-item.Cost))
+	}
+
+	var created rewardOrder
+	if _, err := fetchJSON(rc, fmt.Sprintf("%s/api/rewards/orders/%d", rc.Config.BackendURL, order.OrderID), &created); err != nil {
+		return fmt.Errorf("Objednávka %d - %w", order.OrderID, err)
+	}
+	if created.RewardID != item.ID {
+		errs = append(errs, fmt.Sprintf("objednávka %d je na odměnu %d místo %d", order.OrderID, created.RewardID, item.ID))
+	}
+
+	deadline := time.Now().Add(rewardNotificationTimeout)
+	for {
+		notified, err := hasNotification(rc, func(n userNotification) bool {
+			kind := strings.ToLower(n.Type)
+			return strings.Contains(kind, "reward") || strings.Contains(kind, "redeem")
+		})
+		if err != nil {
+			return fmt.Errorf("Notifikace o uplatnění - %w", err)
+		}
+		if notified {
+			break
+		}
+		if time.Now().After(deadline) {
+			errs = append(errs, fmt.Sprintf("potvrzující notifikace nedorazila do %s", rewardNotificationTimeout))
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	if len(errs) > 0 {
+		return failf(ReasonAssertion, "Uplatnění odměny: %s", strings.Join(errs, "; "))
+	}
+	fmt.Fprintf(rc.Out, "✅ Body odečteny (%d → %d), objednávka %s, notifikace doručena\n", before, after, created.Status)
+	return nil
+}
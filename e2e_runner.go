@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// planItem is one step of a run: either a test or a chaos action. Chaos
+// actions act as barriers; the tests between two barriers run in parallel.
+type planItem struct {
+	test   *testCase
+	action *ChaosAction
+}
+
+// testOutcome is the only value workers hand over to the aggregator.
+type testOutcome struct {
+	index    int
+	name     string
+	suite    string
+	severity Severity
+	err      error
+	attempts int
+	duration time.Duration
+	cpu      time.Duration
+	output   *bytes.Buffer
+	resumed  bool
+	repro    string
+}
+
+func buildPlan(tests []testCase, actions []ChaosAction) []planItem {
+	schedule := chaosSchedule(len(tests), actions)
+	var plan []planItem
+	for i := range tests {
+		plan = append(plan, planItem{test: &tests[i]})
+		for j := range schedule[i] {
+			plan = append(plan, planItem{action: &schedule[i][j]})
+		}
+	}
+	return plan
+}
+
+// runSuite executes the plan and returns the aggregated result. Workers
+// never touch TestResult; they send outcomes to a single aggregator
+// goroutine which owns it, so parallel tests and retries cannot race.
+func runSuite(rc *RunContext, tests []testCase, actions []ChaosAction, state *RunState) TestResult {
+	plan := buildPlan(tests, actions)
+	outcomes := make(chan testOutcome)
+	done := make(chan TestResult)
+	go aggregate(len(plan), outcomes, state, done)
+
+	for start := 0; start < len(plan); {
+		if plan[start].action != nil {
+			action := plan[start].action
+			if o, ok := state.restored(start, "Chaos: "+action.Name); ok {
+				outcomes <- o
+				start++
+				continue
+			}
+			began := time.Now()
+			err := runChaosAction(rc, *action)
+			if err != nil {
+				fmt.Fprintf(rc.Out, "❌ %v\n", err)
+			}
+			outcomes <- testOutcome{index: start, name: "Chaos: " + action.Name, suite: SuiteHarness, severity: SeverityMajor, err: err, attempts: 1, duration: time.Since(began)}
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(plan) && plan[end].test != nil {
+			end++
+		}
+		runBatch(rc, plan, start, end, state, outcomes)
+		start = end
+	}
+
+	close(outcomes)
+	results := <-done
+	// A completed run cannot be resumed, so its state is not kept; a
+	// long-running daemon would otherwise fill -state-dir.
+	if err := state.remove(); err != nil {
+		reportHarnessError(TelemetryReporter, err)
+		fmt.Printf("⚠️ Stav běhu nelze odstranit: %v\n", err)
+	}
+	return results
+}
+
+// runBatch runs plan[start:end] (tests only) on rc.Config.Parallel workers.
+// Tests completed before an interruption are replayed from state.
+func runBatch(rc *RunContext, plan []planItem, start, end int, state *RunState, outcomes chan<- testOutcome) {
+	workers := rc.Config.Parallel
+	if workers < 1 {
+		workers = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				outcomes <- runTest(rc, i, *plan[i].test)
+			}
+		}()
+	}
+
+	for i := start; i < end; i++ {
+		if o, ok := state.restored(i, plan[i].test.name); ok {
+			outcomes <- o
+			continue
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// smokeBudget is the hard limit of a -smoke run, the post-deploy gate.
+const smokeBudget = 60 * time.Second
+
+// budgetTransport binds every request to the run-wide smoke deadline so a
+// hanging backend cannot stretch the run past the budget.
+type budgetTransport struct {
+	next http.RoundTripper
+	ctx  context.Context
+}
+
+// RoundTrip keeps the caller's context, so its own timeout or cancel still
+// applies, and additionally ends the request when the budget runs out.
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	stop := context.AfterFunc(t.ctx, cancel)
+	release := func() {
+		stop()
+		cancel()
+	}
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		release()
+		return nil, err
+	}
+	// An upgraded connection must stay a ReadWriteCloser; it lives until
+	// the budget or the caller's context ends.
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		return resp, nil
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody frees the request context once the body is closed, not when
+// RoundTrip returns, as the body is still read through it.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// startSmokeBudget sets the run deadline and returns the function releasing
+// it once the run is over. Role clients are bound to it as well.
+func startSmokeBudget(rc *RunContext) context.CancelFunc {
+	rc.Deadline = time.Now().Add(smokeBudget)
+	ctx, cancel := context.WithDeadline(context.Background(), rc.Deadline)
+	rc.Client.Transport = &budgetTransport{next: rc.Client.Transport, ctx: ctx}
+	for _, client := range rc.Roles {
+		client.Transport = &budgetTransport{next: client.Transport, ctx: ctx}
+	}
+	return cancel
+}
+
+// runTest runs one test with retries on its own copy of the run context.
+// In parallel runs the output is buffered so it can be printed in one piece.
+func runTest(rc *RunContext, index int, test testCase) testOutcome {
+	trc := *rc
+	// Suites of a split-out service see its base URL as the backend.
+	trc.Config.BackendURL = rc.Config.serviceURL(test.service)
+	outcome := testOutcome{index: index, name: test.name, suite: test.suite, severity: test.severity}
+	if rc.Config.Parallel > 1 {
+		outcome.output = &bytes.Buffer{}
+		trc.Out = outcome.output
+	}
+
+	began, cpu := time.Now(), processCPU()
+	// With -parallel the process CPU covers concurrent tests as well, so
+	// it is only an upper bound per test.
+	finish := func() testOutcome {
+		outcome.duration = time.Since(began)
+		outcome.cpu = processCPU() - cpu
+		return outcome
+	}
+	for {
+		if !rc.Deadline.IsZero() && time.Now().After(rc.Deadline) {
+			outcome.err = failf(ReasonTimeout, "Rozpočet smoke běhu %s vyčerpán", smokeBudget)
+			fmt.Fprintf(trc.Out, "❌ %s: %v\n", test.name, outcome.err)
+			return finish()
+		}
+		outcome.attempts++
+		attempt, rec := trc, &reproRecorder{}
+		if rc.Config.Repro {
+			attempt = recordingContext(&trc, rec)
+		}
+		outcome.err = callTest(test, &attempt)
+		if skipped(outcome.err) {
+			fmt.Fprintf(trc.Out, "⏭️ %s přeskočen: %v\n", test.name, outcome.err)
+			return finish()
+		}
+		if warned(outcome.err) {
+			fmt.Fprintf(trc.Out, "⚠️ %s: %v\n", test.name, outcome.err)
+			return finish()
+		}
+		if outcome.err != nil {
+			for _, line := range strings.Split(outcome.err.Error(), "\n") {
+				fmt.Fprintf(trc.Out, "❌ %s\n", line)
+			}
+		}
+		if outcome.err == nil || outcome.attempts > rc.Config.Retries {
+			if outcome.err != nil && rc.Config.Repro && len(rec.requests) > 0 {
+				path, err := writeRepro(rc.Config, test.name, outcome.err, rec.requests)
+				if err != nil {
+					fmt.Fprintf(trc.Out, "⚠️ Reprodukční skript nelze uložit: %v\n", err)
+				} else {
+					fmt.Fprintf(trc.Out, "🧪 Reprodukce: %s\n", path)
+					outcome.repro = path
+				}
+			}
+			return finish()
+		}
+		fmt.Fprintf(trc.Out, "🔁 Opakuji %s (pokus %d/%d)\n", test.name, outcome.attempts+1, rc.Config.Retries+1)
+	}
+}
+
+func callTest(test testCase, rc *RunContext) (err error) {
+	defer recoverTest(test.name, &err)
+	return test.fn(rc)
+}
+
+// aggregate is the single owner of the run result. It prints buffered test
+// output as outcomes arrive and orders the result by plan position.
+// It also persists every outcome to the run state as soon as it arrives.
+func aggregate(size int, outcomes <-chan testOutcome, state *RunState, done chan<- TestResult) {
+	ordered := make([]*testOutcome, size)
+	for outcome := range outcomes {
+		if outcome.output != nil {
+			os.Stdout.Write(outcome.output.Bytes())
+		}
+		if outcome.resumed {
+			fmt.Printf("⏭️ %s - dokončeno v přerušeném běhu\n", outcome.name)
+		}
+		o := outcome
+		ordered[o.index] = &o
+		state.record(o.index, o.result())
+	}
+
+	results := TestResult{
+		Passed: []string{},
+		Failed: []string{},
+	}
+	for _, o := range ordered {
+		if o == nil {
+			continue
+		}
+		result := o.result()
+		results.Outcomes = append(results.Outcomes, result)
+
+		if result.Skipped {
+			results.Skipped = append(results.Skipped, o.name)
+		} else if result.Passed {
+			results.Passed = append(results.Passed, o.name)
+			if result.Warning {
+				results.Warnings = append(results.Warnings, o.name)
+			}
+			if o.attempts > 1 {
+				results.Flaky = append(results.Flaky, o.name)
+			}
+		} else {
+			results.Failed = append(results.Failed, o.name)
+		}
+	}
+	done <- results
+}
+
+func (o testOutcome) result() TestOutcome {
+	result := TestOutcome{Name: o.name, Suite: o.suite, Passed: o.err == nil || warned(o.err), Severity: o.severity, Attempts: o.attempts, Duration: o.duration, CPU: o.cpu}
+	if skipped(o.err) {
+		result.Skipped = true
+		result.Message = o.err.Error()
+	} else if warned(o.err) {
+		result.Warning = true
+		result.Message = o.err.Error()
+	} else if o.err != nil {
+		result.Reason = reasonOf(o.err)
+		result.Message = strings.ReplaceAll(o.err.Error(), "\n", "; ")
+		result.Repro = o.repro
+	}
+	return result
+}
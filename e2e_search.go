@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// searchResults accepts both a bare list and the {"results": [...]} /
+// {"items": [...]} envelopes.
+func searchResults(body []byte) ([]map[string]interface{}, error) {
+	var list []map[string]interface{}
+	if err := json.Unmarshal(body, &list); err == nil {
+		return list, nil
+	}
+
+	var envelope struct {
+		Results []map[string]interface{} `json:"results"`
+		Items   []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("neplatný JSON: %v", err)
+	}
+	if envelope.Results != nil {
+		return envelope.Results, nil
+	}
+	return envelope.Items, nil
+}
+
+func search(rc *RunContext, query string) ([]map[string]interface{}, error) {
+	resp, err := rc.Client.Get(rc.Config.BackendURL + rc.Config.SearchPath + "?q=" + url.QueryEscape(query))
+	if err != nil {
+		return nil, requestFailure("GET "+rc.Config.SearchPath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, failf(ReasonBadStatus, "GET %s: status %d", rc.Config.SearchPath, resp.StatusCode)
+	}
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return nil, requestFailure("GET "+rc.Config.SearchPath, err)
+	}
+	results, err := searchResults(body)
+	if err != nil {
+		return nil, failf(ReasonSchema, "GET %s: %v", rc.Config.SearchPath, err)
+	}
+	return results, nil
+}
+
+// rankOf returns the 1-based position of the task with id in results, or 0.
+func rankOf(results []map[string]interface{}, id int) int {
+	for i, item := range results {
+		if v, ok := item["id"].(float64); ok && int(v) == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func testSearchRelevance(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🔎 TEST: Search Relevance")
+
+	token := fmt.Sprintf("e2e%d", time.Now().UnixNano()%1000000)
+	exactTitle := "Synchronizace kalendáře " + token
+
+	exactID, err := createTask(rc, exactTitle, "")
+	if err != nil {
+		return fmt.Errorf("Vytvoření tasku selhalo: %w", err)
+	}
+	defer deleteTask(rc, exactID)
+
+	keywordID, err := createTask(rc, "Úklid backlogu "+token, "Poznámka: synchronizace kalendáře "+token)
+	if err != nil {
+		return fmt.Errorf("Vytvoření tasku selhalo: %w", err)
+	}
+	defer deleteTask(rc, keywordID)
+
+	queries := []struct {
+		label string
+		query string
+	}{
+		{"přesný název", exactTitle},
+		{"bez diakritiky", "synchronizace kalendare " + token},
+	}
+
+	for _, q := range queries {
+		results, err := search(rc, q.query)
+		if err != nil {
+			return fmt.Errorf("Hledání (%s) selhalo: %w", q.label, err)
+		}
+
+		exactRank := rankOf(results, exactID)
+		keywordRank := rankOf(results, keywordID)
+		switch {
+		case exactRank == 0:
+			return failf(ReasonAssertion, "Hledání (%s) nenašlo task s přesným názvem", q.label)
+		case keywordRank == 0:
+			return failf(ReasonAssertion, "Hledání (%s) nenašlo task se shodou v popisu", q.label)
+		case exactRank > keywordRank:
+			return failf(ReasonAssertion, "Hledání (%s) řadí shodu v popisu (#%d) nad přesný název (#%d)", q.label, keywordRank, exactRank)
+		}
+		fmt.Fprintf(rc.Out, "✅ Hledání (%s): přesný název #%d, shoda v popisu #%d\n", q.label, exactRank, keywordRank)
+	}
+	return nil
+}
@@ -0,0 +1,556 @@
+package main
+
+import (
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeBackend is an in-memory emulation of the Able2Flow API that covers
+// every endpoint used by the default suite.
+type fakeBackend struct {
+	mu     sync.Mutex
+	nextID int
+	tasks  map[int]map[string]interface{}
+	audit  []map[string]interface{}
+	flags  map[string]bool
+	// degraded, when set, is the failing component reported by /health.
+	degraded string
+	// Rewards store: one sandbox reward, the user's points and orders.
+	points        int
+	orders        []map[string]interface{}
+	notifications []map[string]interface{}
+	// sessions maps issued session cookies to their expiry.
+	sessions map[string]time.Time
+}
+
+func newFakeBackend() *fakeBackend {
+	b := &fakeBackend{nextID: 1, tasks: map[int]map[string]interface{}{}, flags: map[string]bool{"search": true, "data_export": true, "rewards_store": true}, points: 100, sessions: map[string]time.Time{}}
+	b.create("Fix login bug", "Přihlášení padá na Safari")
+	b.create("Implement feature X", "")
+	return b
+}
+
+func (b *fakeBackend) create(title, description string) map[string]interface{} {
+	task := map[string]interface{}{
+		"id":          b.nextID,
+		"title":       title,
+		"description": description,
+		"priority":    "medium",
+		"created_at":  time.Now().Format(time.RFC3339),
+	}
+	b.tasks[b.nextID] = task
+	b.log(b.nextID, "create")
+	b.nextID++
+	return task
+}
+
+// log appends a task audit entry attributed to the selftest user.
+func (b *fakeBackend) log(id int, action string) {
+	b.audit = append(b.audit, map[string]interface{}{
+		"id":          len(b.audit) + 1,
+		"entity_type": "task",
+		"entity_id":   id,
+		"action":      action,
+		"actor":       "selftest",
+		"timestamp":   time.Now().UTC().Format("2006-01-02T15:04:05.000000"),
+	})
+}
+
+func (b *fakeBackend) list() []map[string]interface{} {
+	ids := make([]int, 0, len(b.tasks))
+	for id := range b.tasks {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	list := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, b.tasks[id])
+	}
+	return list
+}
+
+var czechFold = strings.NewReplacer(
+	"á", "a", "č", "c", "ď", "d", "é", "e", "ě", "e", "í", "i", "ň", "n",
+	"ó", "o", "ř", "r", "š", "s", "ť", "t", "ú", "u", "ů", "u", "ý", "y", "ž", "z",
+)
+
+func fold(s string) string {
+	return czechFold.Replace(strings.ToLower(s))
+}
+
+// search ranks exact title matches first, then title and description
+// keyword matches.
+func (b *fakeBackend) search(query string) []map[string]interface{} {
+	q := fold(query)
+	var exact, keyword []map[string]interface{}
+	for _, task := range b.list() {
+		title := fold(task["title"].(string))
+		description := fold(task["description"].(string))
+		switch {
+		case title == q:
+			exact = append(exact, task)
+		case strings.Contains(title, q) || strings.Contains(description, q):
+			keyword = append(keyword, task)
+		}
+	}
+	return append(exact, keyword...)
+}
+
+func (b *fakeBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	w.Header().Set("Server-Timing", `db;dur=0.2;desc="in-memory", app;dur=0.5`)
+
+	path := r.URL.Path
+	switch {
+	case path == "/health":
+		if b.degraded != "" {
+			writeFakeJSON(w, r, map[string]interface{}{"status": "degraded", "components": map[string]interface{}{
+				"database": "ok",
+				b.degraded: map[string]string{"status": "error", "message": "connection refused"},
+			}})
+			return
+		}
+		writeFakeJSON(w, r, map[string]string{"status": "ok", "database": "ok", "monitoring": "active"})
+	case path == "/api/tasks" && r.Method == http.MethodPost:
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeFakeError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON body", err.Error())
+			return
+		}
+		if payload["title"] == "" {
+			writeFakeError(w, http.StatusUnprocessableEntity, "validation_error", "Title is required", map[string]string{"field": "title"})
+			return
+		}
+		writeFakeJSON(w, r, b.create(payload["title"], payload["description"]))
+	case path == "/api/tasks" || path == "/api/tasks/marketplace":
+		if strings.Contains(r.Header.Get("Accept"), "text/csv") {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Vary", "Accept")
+			cw := csv.NewWriter(w)
+			cw.Write([]string{"id", "title", "description"})
+			for _, task := range b.list() {
+				cw.Write([]string{fmt.Sprint(task["id"]), fmt.Sprint(task["title"]), fmt.Sprint(task["description"])})
+			}
+			cw.Flush()
+			return
+		}
+		writeFakeJSON(w, r, b.list())
+	case path == "/api/tasks/search":
+		writeFakeJSON(w, r, b.search(r.URL.Query().Get("q")))
+	case strings.HasPrefix(path, "/api/tasks/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(path, "/api/tasks/"))
+		if err != nil {
+			writeFakeError(w, http.StatusUnprocessableEntity, "validation_error", "Task ID must be a number", map[string]string{"field": "id"})
+			return
+		}
+		task, ok := b.tasks[id]
+		if !ok {
+			message := "Task not found"
+			if strings.HasPrefix(r.Header.Get("Accept-Language"), "cs") {
+				message = "Úkol nenalezen"
+			}
+			writeFakeError(w, http.StatusNotFound, "not_found", message, nil)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			var payload map[string]string
+			json.NewDecoder(r.Body).Decode(&payload)
+			if title, ok := payload["title"]; ok {
+				task["title"] = title
+			}
+			b.log(id, "update")
+		case http.MethodDelete:
+			delete(b.tasks, id)
+			b.log(id, "delete")
+			writeFakeJSON(w, r, map[string]string{"message": "Task deleted"})
+			return
+		}
+		writeFakeJSON(w, r, task)
+	case strings.HasPrefix(path, "/api/admin/dependencies/") && r.Method == http.MethodPost:
+		name, action, _ := strings.Cut(strings.TrimPrefix(path, "/api/admin/dependencies/"), "/")
+		switch action {
+		case "disable":
+			b.degraded = name
+		case "enable":
+			b.degraded = ""
+		default:
+			writeFakeError(w, http.StatusNotFound, "not_found", "Not Found", nil)
+			return
+		}
+		writeFakeJSON(w, r, map[string]interface{}{"name": name, "enabled": action == "enable"})
+	case path == "/api/rewards":
+		price := "1\u00a0234,50\u00a0Kč"
+		if strings.HasPrefix(r.Header.Get("Accept-Language"), "en") {
+			price = "CZK\u00a01,234.50"
+		}
+		writeFakeJSON(w, r, []map[string]interface{}{{"id": 1, "name": "Samolepka", "cost": 10, "sandbox": true, "price": 1234.5, "price_formatted": price}})
+	case path == "/api/rewards/balance":
+		writeFakeJSON(w, r, map[string]interface{}{"points": b.points, "points_formatted": fmt.Sprintf("%d", b.points)})
+	case path == "/api/rewards/1/redeem" && r.Method == http.MethodPost:
+		b.points -= 10
+		order := map[string]interface{}{"id": len(b.orders) + 1, "reward_id": 1, "status": "sandbox"}
+		b.orders = append(b.orders, order)
+		b.notifications = append(b.notifications, map[string]interface{}{"notification_type": "reward_redeemed", "title": "Odměna uplatněna"})
+		writeFakeJSON(w, r, map[string]interface{}{"order_id": order["id"]})
+	case strings.HasPrefix(path, "/api/rewards/orders/"):
+		id, _ := strconv.Atoi(strings.TrimPrefix(path, "/api/rewards/orders/"))
+		if id < 1 || id > len(b.orders) {
+			writeFakeError(w, http.StatusNotFound, "not_found", "Order not found", nil)
+			return
+		}
+		writeFakeJSON(w, r, b.orders[id-1])
+	case path == "/api/auth/login" && r.Method == http.MethodPost:
+		var payload struct {
+			ExpiresIn int `json:"expires_in"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		ttl := time.Hour
+		if payload.ExpiresIn > 0 {
+			ttl = time.Duration(payload.ExpiresIn) * time.Second
+		}
+		session := fmt.Sprintf("s%d", len(b.sessions)+1)
+		b.sessions[session] = time.Now().Add(ttl)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: session, Path: "/"})
+		w.WriteHeader(http.StatusNoContent)
+	case path == "/api/notifications/me":
+		if c, err := r.Cookie("session"); err == nil && time.Now().After(b.sessions[c.Value]) {
+			writeFakeError(w, http.StatusUnauthorized, "unauthorized", "Session expired", nil)
+			return
+		}
+		writeFakeJSON(w, r, append([]map[string]interface{}{}, b.notifications...))
+	case path == "/api/feature-flags":
+		writeFakeJSON(w, r, b.flags)
+	case path == "/api/audit":
+		id, _ := strconv.Atoi(r.URL.Query().Get("entity_id"))
+		entries := []map[string]interface{}{}
+		for i := len(b.audit) - 1; i >= 0; i-- {
+			if id == 0 || b.audit[i]["entity_id"] == id {
+				entries = append(entries, b.audit[i])
+			}
+		}
+		writeFakeJSON(w, r, entries)
+	case path == "/api/notifications/test/create-sample" && r.Method == http.MethodPost:
+		title := "🎯 Jana si vzala task!"
+		if strings.HasPrefix(r.Header.Get("Accept-Language"), "en") {
+			title = "🎯 Jana claimed a task!"
+		}
+		writeFakeJSON(w, r, map[string]interface{}{"id": 1, "title": title})
+	case path == "/api/notifications":
+		writeFakeJSON(w, r, []interface{}{})
+	case path == "/api/leaderboard":
+		writeFakeJSON(w, r, []map[string]interface{}{{"name": "Jana", "points": 42}})
+	case path == "/api/users/me/export":
+		if r.URL.Query().Get("format") == "csv" {
+			w.Header().Set("Content-Type", "text/csv")
+			fmt.Fprintln(w, "id,title")
+			for _, task := range b.list() {
+				fmt.Fprintf(w, "%v,%q\n", task["id"], task["title"])
+			}
+			return
+		}
+		writeFakeJSON(w, r, map[string]interface{}{"user": map[string]string{"id": "selftest"}, "tasks": b.list()})
+	default:
+		writeFakeError(w, http.StatusNotFound, "not_found", "Not Found", nil)
+	}
+}
+
+// writeFakeError writes the standard error envelope.
+func writeFakeError(w http.ResponseWriter, status int, code, message string, details interface{}) {
+	requestID := fmt.Sprintf("req-%d", time.Now().UnixNano())
+	body, _ := json.Marshal(map[string]interface{}{"code": code, "message": message, "details": details, "request_id": requestID})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Request-ID", requestID)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// writeFakeJSON writes v with a content-derived ETag and honours
+// If-None-Match.
+func writeFakeJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	if accept := r.Header.Get("Accept"); accept != "" && !strings.Contains(accept, "json") && !strings.Contains(accept, "*/*") {
+		writeFakeError(w, http.StatusNotAcceptable, "not_acceptable", "Supported formats: application/json", nil)
+		return
+	}
+	body, _ := json.Marshal(v)
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum(body))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// fakeFrontend serves an index page whose runtime config points at apiBase.
+func fakeFrontend(apiBase string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/", "/login":
+		case "/robots.txt":
+			fmt.Fprintf(w, "User-agent: *\nAllow: /\nSitemap: http://%s/sitemap.xml\n", r.Host)
+			return
+		case "/sitemap.xml":
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>http://%s/</loc></url></urlset>`, r.Host)
+			return
+		case "/favicon.ico":
+			w.Write([]byte{0, 0, 1, 0, 1, 0, 1, 1, 0, 0, 1, 0, 32, 0})
+			return
+		case "/assets/app.js":
+			writeFakeAsset(w, r, "text/javascript", strings.Repeat("console.log('able2flow');\n", 100))
+			return
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, "<!doctype html><title>Able2Flow</title><script>window.__ENV__ = {\"VITE_API_URL\": %q};</script><div id=\"app\"></div><script type=\"module\" src=\"/assets/app.js\"></script>", apiBase)
+	})
+}
+
+// writeFakeAsset serves a pre-compressed asset like a CDN: gzip when the
+// client accepts it, identity otherwise.
+func writeFakeAsset(w http.ResponseWriter, r *http.Request, contentType, content string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Vary", "Accept-Encoding")
+	if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		io.WriteString(w, content)
+		return
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+	zw := gzip.NewWriter(w)
+	io.WriteString(zw, content)
+	zw.Close()
+}
+
+func slowHandler(delay time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func brokenHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	})
+}
+
+// bearerHandler rejects requests without the expected bearer token, like
+// the API gateway in front of staging.
+func bearerHandler(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, `{"detail":"Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// oversizedHandler streams an endless body until the client hangs up.
+func oversizedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		chunk := []byte(strings.Repeat("a", 64<<10))
+		w.Write([]byte(`{"status":"ok","pad":"`))
+		for {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	})
+}
+
+type selftestScenario struct {
+	name    string
+	backend http.Handler
+	// frontend builds the frontend handler once the backend URL is known.
+	frontend   func(backendURL string) http.Handler
+	expectPass bool
+	// expectReason, when set, is the failure reason every failed test must carry.
+	expectReason FailureReason
+	args         []string
+	// expectSkipped lists the tests the feature flags must skip.
+	expectSkipped []string
+	// expectWarnings lists the tests that must pass with a warning.
+	expectWarnings []string
+}
+
+// selftestCommand runs the whole runner and reporter pipeline against
+// emulated healthy, degraded, slow, broken, oversized and gateway-protected backends
+// and checks the outcome of each.
+func selftestCommand(args []string) int {
+	fs := flag.NewFlagSet("selftest", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", 300*time.Millisecond, "timeout požadavku během selftestu")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	dir, err := os.MkdirTemp("", "able2flow-selftest-")
+	if err != nil {
+		fmt.Printf("❌ Nelze vytvořit dočasný adresář: %v\n", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	profile := filepath.Join(dir, "profiles.json")
+	os.Setenv("ABLE2FLOW_SELFTEST_TOKEN", "selftest-token")
+	os.Setenv("ABLE2FLOW_SELFTEST_PASSWORD", "selftest-password")
+	err = os.WriteFile(profile, []byte(`{"environments":{
+		"gateway":{"auth":{"type":"bearer","token_env":"ABLE2FLOW_SELFTEST_TOKEN"}},
+		"session":{"roles":{"user":{"type":"session","username":"selftest","password_env":"ABLE2FLOW_SELFTEST_PASSWORD","login_path":"/api/auth/login"}}}}}`), 0644)
+	if err != nil {
+		fmt.Printf("❌ Nelze zapsat profil prostředí: %v\n", err)
+		return 1
+	}
+
+	budgets := filepath.Join(dir, "budgets.yaml")
+	if err := os.WriteFile(budgets, []byte("smoke: 60s # post-deploy gate\n"), 0644); err != nil {
+		fmt.Printf("❌ Nelze zapsat rozpočty: %v\n", err)
+		return 1
+	}
+
+	// The examples embedded for init must stay loadable.
+	examples := filepath.Join(dir, "examples")
+	if _, err := writeExamples(examples, false); err != nil {
+		fmt.Printf("❌ Nelze zapsat vestavěné příklady: %v\n", err)
+		return 1
+	}
+	if _, err := parseConfig("selftest", []string{"-config", filepath.Join(examples, "profiles.json"), "-env", "dev", "-budget", filepath.Join(examples, "budgets.yaml")}); err != nil {
+		fmt.Printf("❌ Vestavěné příklady nejsou platné: %v\n", err)
+		return 1
+	}
+
+	frontend := func(backendURL string) http.Handler { return fakeFrontend(backendURL + "/api") }
+	slowFrontend := func(backendURL string) http.Handler { return slowHandler(2**timeout, fakeFrontend(backendURL+"/api")) }
+	broken := func(string) http.Handler { return brokenHandler() }
+	oversized := func(string) http.Handler { return oversizedHandler() }
+	flagged := newFakeBackend()
+	flagged.flags["search"] = false
+	degraded := newFakeBackend()
+	degraded.degraded = "cache"
+
+	scenarios := []selftestScenario{
+		{"healthy", newFakeBackend(), frontend, true, "", nil, nil, nil},
+		{"parallel", newFakeBackend(), frontend, true, "", []string{"-parallel", "4", "-cost-report", filepath.Join(dir, "cost.json")}, nil, nil},
+		{"smoke", newFakeBackend(), frontend, true, "", []string{"-smoke", "-budget", budgets, "-timing-file", filepath.Join(dir, "timing.json")}, nil, nil},
+		{"gateway", bearerHandler("selftest-token", newFakeBackend()), frontend, true, "", []string{"-config", profile, "-env", "gateway"}, nil, nil},
+		{"slow", slowHandler(2**timeout, newFakeBackend()), slowFrontend, false, ReasonTimeout, nil, nil, nil},
+		{"broken", brokenHandler(), broken, false, ReasonBadStatus, nil, nil, nil},
+		{"oversized", oversizedHandler(), oversized, false, ReasonSchema, []string{"-max-body", "1048576"}, nil, nil},
+		{"flags", flagged, frontend, true, "", nil, []string{"Search Relevance"}, nil},
+		{"degraded", degraded, frontend, true, "", nil, nil, []string{"Backend Health"}},
+		{"dependency", newFakeBackend(), frontend, true, "", []string{"-dependency-toggle", "email"}, nil, nil},
+		{"session", newFakeBackend(), frontend, true, "", []string{"-config", profile, "-env", "session", "-stale-token-ttl", "1s"}, nil, nil},
+	}
+
+	failed := 0
+	for _, sc := range scenarios {
+		fmt.Printf("\n🧪 SELFTEST: %s backend\n", sc.name)
+		if !runSelftestScenario(sc, *timeout, dir) {
+			failed++
+		}
+	}
+
+	fmt.Println("\n============================================================")
+	if failed > 0 {
+		fmt.Printf("❌ Selftest selhal: %d/%d scénářů\n", failed, len(scenarios))
+		fmt.Println("============================================================")
+		return 1
+	}
+	fmt.Printf("✅ Selftest OK: %d/%d scénářů\n", len(scenarios), len(scenarios))
+	fmt.Println("============================================================")
+	return 0
+}
+
+func runSelftestScenario(sc selftestScenario, timeout time.Duration, dir string) bool {
+	backend := httptest.NewServer(sc.backend)
+	defer backend.Close()
+	frontend := httptest.NewServer(sc.frontend(backend.URL))
+	defer frontend.Close()
+
+	cfg, err := parseConfig("selftest", append([]string{
+		"-backend", backend.URL,
+		"-frontend", frontend.URL,
+		"-timeout", timeout.String(),
+		"-report", filepath.Join(dir, sc.name+".txt"),
+		"-state-dir", filepath.Join(dir, "runs"),
+	}, sc.args...))
+	if err != nil {
+		fmt.Printf("❌ Selftest %s - konfigurace: %v\n", sc.name, err)
+		return false
+	}
+
+	results, err := executeRun(cfg)
+	if err != nil {
+		fmt.Printf("❌ Selftest %s - běh selhal: %v\n", sc.name, err)
+		return false
+	}
+
+	if sc.expectPass && len(results.Failed) > 0 {
+		fmt.Printf("❌ Selftest %s - očekáván úspěch, selhalo: %s\n", sc.name, strings.Join(results.Failed, ", "))
+		return false
+	}
+	// The harness gate must catch races itself, not hide them behind retries.
+	if len(results.Flaky) > 0 {
+		fmt.Printf("❌ Selftest %s - nestabilní testy: %s\n", sc.name, strings.Join(results.Flaky, ", "))
+		return false
+	}
+	if !sc.expectPass && len(results.Passed) > 0 {
+		fmt.Printf("❌ Selftest %s - očekáváno selhání, prošlo: %s\n", sc.name, strings.Join(results.Passed, ", "))
+		return false
+	}
+
+	if sc.expectPass && strings.Join(results.Skipped, ",") != strings.Join(sc.expectSkipped, ",") {
+		fmt.Printf("❌ Selftest %s - přeskočeno %v, očekáváno %v\n", sc.name, results.Skipped, sc.expectSkipped)
+		return false
+	}
+
+	if sc.expectPass && strings.Join(results.Warnings, ",") != strings.Join(sc.expectWarnings, ",") {
+		fmt.Printf("❌ Selftest %s - varování u %v, očekáváno %v\n", sc.name, results.Warnings, sc.expectWarnings)
+		return false
+	}
+
+	if sc.expectPass && results.Timing.WithHeader == 0 {
+		fmt.Printf("❌ Selftest %s - nezachycen žádný Server-Timing header\n", sc.name)
+		return false
+	}
+
+	for _, o := range failedOutcomes(results) {
+		if sc.expectReason != "" && o.Reason != sc.expectReason {
+			fmt.Printf("❌ Selftest %s - %s selhal s příčinou %s místo %s\n", sc.name, o.Name, o.Reason, sc.expectReason)
+			return false
+		}
+	}
+
+	report, err := os.ReadFile(cfg.ReportPath)
+	total, rate := successRate(results)
+	summary := fmt.Sprintf("Úspěšnost: %d/%d (%d%%)", len(results.Passed), total, rate)
+	if err != nil || !strings.Contains(string(report), summary) {
+		fmt.Printf("❌ Selftest %s - report neobsahuje %q\n", sc.name, summary)
+		return false
+	}
+
+	fmt.Printf("✅ Selftest %s - %d prošlo, %d selhalo, report OK\n", sc.name, len(results.Passed), len(results.Failed))
+	return true
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Backend services a split deployment may run separately. Undeclared
+// services are served by -backend.
+const (
+	ServiceTasks         = "tasks"
+	ServiceNotifications = "notifications"
+	ServiceUsers         = "users"
+)
+
+// serviceURL returns the base URL of service, or -backend when the service
+// is not declared.
+func (cfg Config) serviceURL(service string) string {
+	if url, ok := cfg.Services[service]; ok {
+		return url
+	}
+	return cfg.BackendURL
+}
+
+// backendURLs lists -backend and every declared service. Requests to any of
+// them carry auth and count as backend calls in the scorecard.
+func (cfg Config) backendURLs() []string {
+	urls := []string{cfg.BackendURL}
+	for _, name := range serviceNames(cfg.Services) {
+		urls = append(urls, cfg.Services[name])
+	}
+	return urls
+}
+
+func serviceNames(services map[string]string) []string {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matchesBase reports whether target lies under one of the base URLs: same
+// scheme and host, and a path below the base path. Comparing raw strings
+// would let http://host:8000 match http://host:80001.
+func matchesBase(target string, bases []string) bool {
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	for _, base := range bases {
+		b, err := url.Parse(base)
+		if err != nil || !strings.EqualFold(u.Scheme, b.Scheme) || !strings.EqualFold(u.Host, b.Host) {
+			continue
+		}
+		prefix := strings.TrimSuffix(b.Path, "/")
+		if prefix == "" || u.Path == prefix || strings.HasPrefix(u.Path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseService parses a -service name=url flag value.
+func parseService(services map[string]string, value string) error {
+	name, url, ok := strings.Cut(value, "=")
+	if !ok || name == "" || url == "" {
+		return fmt.Errorf("očekáváno služba=url, např. tasks=http://localhost:8001: %q", value)
+	}
+	services[name] = strings.TrimSuffix(url, "/")
+	return nil
+}
+
+func formatServices(services map[string]string) string {
+	var items []string
+	for _, name := range serviceNames(services) {
+		items = append(items, name+"="+services[name])
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// sessionProbePath is an endpoint that always needs a signed-in user.
+const sessionProbePath = "/api/notifications/me"
+
+// shortSession is a login of the user role with a short-lived token. The
+// backend sets a session cookie or returns a bearer token in the body.
+type shortSession struct {
+	cookies []*http.Cookie
+	token   string
+}
+
+func (s shortSession) apply(req *http.Request) {
+	for _, c := range s.cookies {
+		req.AddCookie(c)
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+}
+
+// loginShortLived logs in as auth asking for a token valid for ttl. Backends
+// that ignore expires_in are aged with -test-clock-path instead.
+func loginShortLived(rc *RunContext, client *http.Client, auth AuthConfig, ttl time.Duration) (shortSession, error) {
+	url := rc.Config.BackendURL + auth.LoginPath
+	payload, _ := json.Marshal(map[string]interface{}{"username": auth.Username, "password": auth.Password, "expires_in": int(ttl.Seconds())})
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return shortSession{}, requestFailure("POST "+url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		discardBody(rc, resp)
+		return shortSession{}, failf(ReasonBadStatus, "POST %s: status %d", url, resp.StatusCode)
+	}
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return shortSession{}, requestFailure("POST "+url, err)
+	}
+	var tokens struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	json.Unmarshal(body, &tokens)
+	s := shortSession{cookies: resp.Cookies(), token: tokens.AccessToken}
+	if s.token == "" {
+		s.token = tokens.Token
+	}
+	if len(s.cookies) == 0 && s.token == "" {
+		return s, failf(ReasonSchema, "POST %s: odpověď nenastavila cookie ani token", url)
+	}
+	return s, nil
+}
+
+// advanceClock moves the backend test clock, so the token expires without
+// waiting. A zero duration resets the clock.
+func advanceClock(rc *RunContext, client *http.Client, d time.Duration) error {
+	payload := map[string]interface{}{"advance_seconds": int(d.Seconds())}
+	if d == 0 {
+		payload = map[string]interface{}{"reset": true}
+	}
+	data, _ := json.Marshal(payload)
+	url := rc.Config.BackendURL + rc.Config.TestClockPath
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return requestFailure("POST "+url, err)
+	}
+	discardBody(rc, resp)
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return failf(ReasonBadStatus, "POST %s: status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+func sessionStatus(rc *RunContext, client *http.Client, s shortSession) (int, []byte, error) {
+	req, _ := http.NewRequest(http.MethodGet, rc.Config.BackendURL+sessionProbePath, nil)
+	s.apply(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, requestFailure("GET "+sessionProbePath, err)
+	}
+	defer resp.Body.Close()
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return resp.StatusCode, nil, requestFailure("GET "+sessionProbePath, err)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// testSessionExpiry logs in with a token living -stale-token-ttl, ages it past
+// the TTL and checks that the API answers 401 instead of serving data or
+// failing with a 5xx. The frontend redirect to login needs a browser, which
+// the harness does not drive, so only the login route is checked there.
+func testSessionExpiry(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n⌛ TEST: Session Expiry")
+	ttl := rc.Config.StaleTokenTTL
+	// Without the harness auth, so only the short-lived credentials count.
+	client := &http.Client{Timeout: rc.Config.RequestTimeout}
+
+	session, err := loginShortLived(rc, client, rc.Config.Roles[RoleUser], ttl)
+	if err != nil {
+		return fmt.Errorf("Přihlášení s krátkým tokenem: %w", err)
+	}
+	status, _, err := sessionStatus(rc, client, session)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return failf(ReasonBadStatus, "GET %s s čerstvým tokenem: status %d", sessionProbePath, status)
+	}
+
+	age := ttl + time.Second
+	if rc.Config.TestClockPath != "" {
+		if err := advanceClock(rc, client, age); err != nil {
+			return fmt.Errorf("Posun testovacích hodin: %w", err)
+		}
+		defer advanceClock(rc, client, 0)
+		fmt.Fprintf(rc.Out, "   Testovací hodiny posunuty o %s\n", age)
+	} else {
+		fmt.Fprintf(rc.Out, "   Čekám %s, než token vyprší...\n", age)
+		time.Sleep(age)
+	}
+
+	status, body, err := sessionStatus(rc, client, session)
+	if err != nil {
+		return err
+	}
+	switch {
+	case status == http.StatusOK:
+		return failf(ReasonAssertion, "GET %s: token po %s stále platí", sessionProbePath, age)
+	case status != http.StatusUnauthorized:
+		return failf(ReasonBadStatus, "GET %s s prošlým tokenem: status %d místo 401", sessionProbePath, status)
+	}
+	env, err := decodeEnvelope(body)
+	if err != nil || env.text() == "" {
+		return failf(ReasonSchema, "GET %s: 401 bez chybové zprávy, frontend nepozná důvod odhlášení", sessionProbePath)
+	}
+	fmt.Fprintf(rc.Out, "✅ Prošlý token odmítnut: 401 %s\n", env.text())
+
+	status, page, err := fetchFrontend(rc, "/login")
+	if err != nil {
+		return fmt.Errorf("Přihlašovací stránka: %w", err)
+	}
+	html := strings.ToLower(string(page))
+	if status != http.StatusOK || !strings.Contains(html, "<html") && !strings.Contains(html, "<!doctype html") {
+		return failf(ReasonBadStatus, "Frontend /login: status %d, přesměrování po vypršení nemá kam vést", status)
+	}
+	fmt.Fprintln(rc.Out, "   Frontend /login dostupný; přesměrování v prohlížeči harness neověřuje")
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import "fmt"
+
+// Severity says how much a failing test matters. Failures below the -fail-on
+// threshold are reported as warnings and do not fail the run.
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityMajor    Severity = "major"
+	SeverityMinor    Severity = "minor"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityCritical:
+		return 3
+	case SeverityMajor:
+		return 2
+	case SeverityMinor:
+		return 1
+	}
+	return 0
+}
+
+func parseSeverity(value string) (Severity, error) {
+	s := Severity(value)
+	if s.rank() == 0 {
+		return "", fmt.Errorf("neplatná severita: %s (critical, major, minor)", value)
+	}
+	return s, nil
+}
+
+// blocks reports whether a failure of this severity fails the run.
+func (s Severity) blocks(threshold Severity) bool {
+	return s.rank() >= threshold.rank()
+}
+
+// blockingFailures returns the failed outcomes that fail the run under the
+// given threshold.
+func blockingFailures(results TestResult, threshold Severity) []TestOutcome {
+	var blocking []TestOutcome
+	for _, o := range failedOutcomes(results) {
+		if o.Severity.blocks(threshold) {
+			blocking = append(blocking, o)
+		}
+	}
+	return blocking
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Shard selects every Total-th test of the registry starting at Index, so
+// parallel CI jobs with the same registry split it without overlap. The zero
+// value runs everything.
+type Shard struct {
+	Index int
+	Total int
+}
+
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Total)
+}
+
+func parseShard(value string) (Shard, error) {
+	index, total, ok := strings.Cut(value, "/")
+	k, errK := strconv.Atoi(index)
+	n, errN := strconv.Atoi(total)
+	if !ok || errK != nil || errN != nil || n < 1 || k < 1 || k > n {
+		return Shard{}, fmt.Errorf("očekáváno k/n s 1 <= k <= n, např. 2/5: %q", value)
+	}
+	return Shard{k, n}, nil
+}
+
+func shardTests(tests []testCase, shard Shard) []testCase {
+	if shard.Total == 0 {
+		return tests
+	}
+	var selected []testCase
+	for i, test := range tests {
+		if i%shard.Total == shard.Index-1 {
+			selected = append(selected, test)
+		}
+	}
+	return selected
+}
+
+// mergeCommand combines the JSON reports of all shards of a run into one
+// text and JSON report, and fails like run would on the combined result.
+func mergeCommand(args []string) int {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	var failOn, tz, signKey, pubKey string
+	cfg := Config{}
+	fs.StringVar(&cfg.ReportPath, "report", "/Users/lhradek/code/work/flowable/e2e_test_report.txt", "cesta sloučeného textového reportu")
+	fs.StringVar(&cfg.JSONReportPath, "json-report", "", "cesta sloučeného JSON reportu")
+	fs.StringVar(&signKey, "sign-key", "", "Ed25519 privátní klíč (PKCS #8 PEM) pro podpis sloučeného JSON reportu")
+	fs.StringVar(&pubKey, "pubkey", "", "veřejný Ed25519 klíč (PEM), kterým se před sloučením ověří <shard>.sig každého shardu; povinný s -sign-key")
+	fs.StringVar(&failOn, "fail-on", string(SeverityMajor), "nejnižší severita selhání, která shodí běh (critical, major, minor)")
+	fs.StringVar(&tz, "tz", "UTC", "časová zóna časových údajů v reportech")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Použití: merge [volby] shard-1.json shard-2.json ...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	var err error
+	if cfg.FailOn, err = parseSeverity(failOn); err != nil {
+		fmt.Fprintf(fs.Output(), "❌ -fail-on: %v\n", err)
+		return 2
+	}
+	if cfg.Location, err = time.LoadLocation(tz); err != nil {
+		fmt.Fprintf(fs.Output(), "❌ -tz: %v\n", err)
+		return 2
+	}
+	if signKey != "" {
+		if cfg.SignKey, err = loadSigningKey(signKey); err != nil {
+			fmt.Fprintf(fs.Output(), "❌ -sign-key: %v\n", err)
+			return 2
+		}
+		// Signing unverified shards would launder edits into a valid artifact.
+		if pubKey == "" {
+			fmt.Fprintln(fs.Output(), "❌ -sign-key vyžaduje -pubkey pro ověření shardů")
+			return 2
+		}
+	}
+	var pub ed25519.PublicKey
+	if pubKey != "" {
+		if pub, err = loadVerifyKey(pubKey); err != nil {
+			fmt.Fprintf(fs.Output(), "❌ -pubkey: %v\n", err)
+			return 2
+		}
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var reports []jsonReport
+	for _, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return 2
+		}
+		if pub != nil {
+			raw, err := os.ReadFile(path + ".sig")
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return 2
+			}
+			if err := verifyArtifact(pub, path, data, raw); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return 1
+			}
+		}
+		var report jsonReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			fmt.Printf("❌ %s: neplatný JSON report: %v\n", path, err)
+			return 2
+		}
+		reports = append(reports, report)
+		fmt.Printf("📥 %s: shard %s, %d testů\n", path, report.Shard, len(report.Tests))
+	}
+	cfg.BackendURL, cfg.FrontendURL = reports[0].Backend, reports[0].Frontend
+
+	results, err := mergeReports(reports)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 2
+	}
+	printReport(cfg, results)
+	saveReport(cfg, results)
+	if cfg.JSONReportPath != "" {
+		saveJSONReport(cfg, results)
+	}
+	if len(blockingFailures(results, cfg.FailOn)) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// mergeReports rebuilds one run result from shard reports. A shard missing
+// from the set counts as a failed harness outcome, so a lost CI job cannot
+// pass silently. Percentiles cannot be merged exactly; the worst shard's
+// p95 stands in for the combined one. Shards of different runs, different
+// splits or the same shard given twice are rejected.
+func mergeReports(reports []jsonReport) (TestResult, error) {
+	results := TestResult{Passed: []string{}, Failed: []string{}, RunID: reports[0].RunID}
+	seen := map[int]bool{}
+	total := 0
+	phases := map[string]*PhaseTiming{}
+	endpoints := map[string]*EndpointScore{}
+	var roundTrip, server, network time.Duration
+
+	for _, r := range reports {
+		if r.RunID != results.RunID {
+			return results, fmt.Errorf("shard %s je z běhu %q, ostatní z %q (shardy spouštějte se společným -run-id)", r.Shard, r.RunID, results.RunID)
+		}
+		if shard, err := parseShard(r.Shard); err == nil {
+			if total != 0 && shard.Total != total {
+				return results, fmt.Errorf("shard %s nepatří do rozdělení na %d shardů", r.Shard, total)
+			}
+			if seen[shard.Index] {
+				return results, fmt.Errorf("shard %s je zadán dvakrát", r.Shard)
+			}
+			seen[shard.Index] = true
+			total = shard.Total
+		}
+		if started, err := time.Parse(time.RFC3339, r.Started); err == nil && (results.Started.IsZero() || started.Before(results.Started)) {
+			results.Started = started
+		}
+		for flag, on := range r.FeatureFlags {
+			if results.FeatureFlags == nil {
+				results.FeatureFlags = map[string]bool{}
+			}
+			results.FeatureFlags[flag] = on
+		}
+
+		for _, t := range r.Tests {
+			o := TestOutcome{
+				Name:     t.Name,
+				Suite:    t.Suite,
+				Passed:   t.Status == "passed" || t.Status == "warning",
+				Skipped:  t.Status == "skipped",
+				Warning:  t.Status == "warning",
+				Severity: t.Severity,
+				Reason:   t.Reason,
+				Message:  t.Message,
+				Repro:    t.Repro,
+				Attempts: t.Attempts,
+				Duration: time.Duration(t.DurationMs) * time.Millisecond,
+			}
+			results.Outcomes = append(results.Outcomes, o)
+			switch {
+			case o.Skipped:
+				results.Skipped = append(results.Skipped, o.Name)
+			case o.Passed:
+				results.Passed = append(results.Passed, o.Name)
+				if o.Warning {
+					results.Warnings = append(results.Warnings, o.Name)
+				}
+				if o.Attempts > 1 {
+					results.Flaky = append(results.Flaky, o.Name)
+				}
+			default:
+				results.Failed = append(results.Failed, o.Name)
+			}
+		}
+
+		st := r.ServerTiming
+		results.Timing.Requests += st.Requests
+		results.Timing.WithHeader += st.WithHeader
+		roundTrip += msDuration(st.AvgRoundTripMs) * time.Duration(st.Requests)
+		server += msDuration(st.AvgServerMs) * time.Duration(st.WithHeader)
+		network += msDuration(st.AvgNetworkMs) * time.Duration(st.WithHeader)
+		for _, p := range st.Phases {
+			merged, ok := phases[p.Name]
+			if !ok {
+				merged = &PhaseTiming{Name: p.Name}
+				phases[p.Name] = merged
+			}
+			merged.Avg += msDuration(p.AvgMs) * time.Duration(p.Count)
+			merged.Count += p.Count
+			merged.P95 = max(merged.P95, msDuration(p.P95Ms))
+		}
+		for _, e := range r.Scorecard {
+			merged, ok := endpoints[e.Endpoint]
+			if !ok {
+				merged = &EndpointScore{Endpoint: e.Endpoint}
+				endpoints[e.Endpoint] = merged
+			}
+			merged.Calls += e.Calls
+			merged.Errors += e.Errors
+			merged.ClientErrors += e.ClientErrors
+			merged.P95 = max(merged.P95, msDuration(e.P95Ms))
+		}
+	}
+
+	for k := 1; k <= total; k++ {
+		if !seen[k] {
+			name := fmt.Sprintf("Shard %d/%d", k, total)
+			results.Failed = append(results.Failed, name)
+			results.Outcomes = append(results.Outcomes, TestOutcome{Name: name, Suite: SuiteHarness, Severity: SeverityCritical, Reason: ReasonHarness, Message: "chybí výsledky shardu"})
+		}
+	}
+
+	if n := results.Timing.Requests; n > 0 {
+		results.Timing.AvgRoundTrip = roundTrip / time.Duration(n)
+	}
+	if n := results.Timing.WithHeader; n > 0 {
+		results.Timing.AvgServer = server / time.Duration(n)
+		results.Timing.AvgNetwork = network / time.Duration(n)
+	}
+	for _, p := range phases {
+		if p.Count > 0 {
+			p.Avg /= time.Duration(p.Count)
+		}
+		results.Timing.Phases = append(results.Timing.Phases, *p)
+	}
+	sort.Slice(results.Timing.Phases, func(i, j int) bool { return results.Timing.Phases[i].Name < results.Timing.Phases[j].Name })
+	for _, e := range endpoints {
+		results.Scorecard = append(results.Scorecard, *e)
+	}
+	sortScores(results.Scorecard)
+	return results, nil
+}
+
+func msDuration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// artifactSignature is the detached signature written next to a signed JSON
+// report as <report>.sig. It covers the exact bytes of the report.
+type artifactSignature struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	Signature string `json:"signature"`
+}
+
+// keyID identifies a public key by the start of its SHA-256.
+func keyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+func readPEM(path, kind string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: soubor není PEM", path)
+	}
+	if block.Type != kind {
+		return nil, fmt.Errorf("%s: očekáván %s, soubor obsahuje %s", path, kind, block.Type)
+	}
+	return block.Bytes, nil
+}
+
+// loadSigningKey reads an Ed25519 private key in PKCS #8 PEM, as written by
+// openssl genpkey -algorithm ed25519.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: očekáván Ed25519 klíč, ne %T", path, key)
+	}
+	return priv, nil
+}
+
+// loadVerifyKey reads an Ed25519 public key in PKIX PEM, as written by
+// openssl pkey -pubout.
+func loadVerifyKey(path string) (ed25519.PublicKey, error) {
+	der, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: očekáván Ed25519 klíč, ne %T", path, key)
+	}
+	return pub, nil
+}
+
+// signArtifact writes the detached signature of data to path.sig.
+func signArtifact(key ed25519.PrivateKey, path string, data []byte) error {
+	sig := artifactSignature{
+		Algorithm: "ed25519",
+		KeyID:     keyID(key.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)),
+	}
+	out, _ := json.MarshalIndent(sig, "", "  ")
+	return os.WriteFile(path+".sig", out, 0644)
+}
+
+// verifyArtifact checks the detached signature raw of the report bytes data
+// against pub.
+func verifyArtifact(pub ed25519.PublicKey, report string, data, raw []byte) error {
+	var sig artifactSignature
+	if err := json.Unmarshal(raw, &sig); err != nil || sig.Algorithm != "ed25519" {
+		return fmt.Errorf("%s: neplatný podpis", report)
+	}
+	if sig.KeyID != keyID(pub) {
+		return fmt.Errorf("%s je podepsán klíčem %s, ne %s", report, sig.KeyID, keyID(pub))
+	}
+	signature, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil || !ed25519.Verify(pub, data, signature) {
+		return fmt.Errorf("%s: podpis nesouhlasí, report byl po běhu změněn", report)
+	}
+	return nil
+}
+
+// verifyCommand checks a signed JSON report against a public key, so release
+// tooling can reject artifacts edited after the run.
+func verifyCommand(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	pubPath := fs.String("pubkey", "", "veřejný Ed25519 klíč (PEM) odpovídající -sign-key")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Použití: verify -pubkey key.pub.pem report.json [report.json.sig]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *pubPath == "" || fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		return 2
+	}
+	pub, err := loadVerifyKey(*pubPath)
+	if err != nil {
+		fmt.Printf("❌ -pubkey: %v\n", err)
+		return 2
+	}
+	report := fs.Arg(0)
+	sigPath := report + ".sig"
+	if fs.NArg() == 2 {
+		sigPath = fs.Arg(1)
+	}
+
+	data, err := os.ReadFile(report)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 2
+	}
+	raw, err := os.ReadFile(sigPath)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 2
+	}
+	if err := verifyArtifact(pub, report, data, raw); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	fmt.Printf("✅ %s: podpis platný (klíč %s)\n", report, keyID(pub))
+	return 0
+}
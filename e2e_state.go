@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RunState is the on-disk progress of a run. The aggregator saves it after
+// every outcome, so `run -resume <runid>` can re-execute only the tests that
+// had not completed when the run was interrupted. It is removed once the run
+// completes.
+type RunState struct {
+	RunID     string              `json:"run_id"`
+	Args      []string            `json:"args"`
+	Started   time.Time           `json:"started"`
+	Completed map[int]TestOutcome `json:"completed"`
+	// FeatureFlags is the flag snapshot taken at run start.
+	FeatureFlags map[string]bool `json:"feature_flags,omitempty"`
+
+	path string
+	// prior holds the outcomes loaded on resume. It is read-only, while
+	// Completed is owned by the aggregator.
+	prior map[int]TestOutcome
+}
+
+func newRunID() string {
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+func statePath(dir, runID string) string {
+	return filepath.Join(dir, runID+".json")
+}
+
+func loadRunState(dir, runID string) (*RunState, error) {
+	path := statePath(dir, runID)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("běh %s nelze obnovit: %w", runID, err)
+	}
+	state := &RunState{path: path}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	state.prior = state.Completed
+	state.Completed = map[int]TestOutcome{}
+	return state, nil
+}
+
+// resumeConfig rebuilds the configuration of the interrupted run from its
+// saved arguments. Only -state-dir and -resume are taken from the command line.
+func resumeConfig(cfg Config) (Config, error) {
+	state, err := loadRunState(cfg.StateDir, cfg.ResumeID)
+	if err != nil {
+		return cfg, err
+	}
+	resumed, err := parseConfig("run", state.Args)
+	if err != nil {
+		return cfg, fmt.Errorf("uložené argumenty běhu %s: %w", cfg.ResumeID, err)
+	}
+	resumed.StateDir = cfg.StateDir
+	resumed.ResumeID = cfg.ResumeID
+	return resumed, nil
+}
+
+// openRunState loads the state of the resumed run or starts a new one.
+func openRunState(cfg Config) (*RunState, error) {
+	if cfg.ResumeID != "" {
+		return loadRunState(cfg.StateDir, cfg.ResumeID)
+	}
+	if err := os.MkdirAll(cfg.StateDir, 0755); err != nil {
+		return nil, err
+	}
+	runID := cfg.RunID
+	if runID == "" {
+		runID = newRunID()
+	} else if _, err := os.Stat(statePath(cfg.StateDir, runID)); err == nil {
+		return nil, fmt.Errorf("běh %s už v %s existuje (pokračování: -resume %s)", runID, cfg.StateDir, runID)
+	}
+	state := &RunState{
+		RunID:     runID,
+		Args:      cfg.Args,
+		Started:   time.Now(),
+		Completed: map[int]TestOutcome{},
+		path:      statePath(cfg.StateDir, runID),
+	}
+	return state, state.save()
+}
+
+// restored returns the outcome of plan item index from the interrupted run,
+// provided the plan still has the same item at that position.
+func (s *RunState) restored(index int, name string) (testOutcome, bool) {
+	prior, ok := s.prior[index]
+	if !ok || prior.Name != name {
+		return testOutcome{}, false
+	}
+	o := testOutcome{
+		index:    index,
+		name:     prior.Name,
+		suite:    prior.Suite,
+		severity: prior.Severity,
+		attempts: prior.Attempts,
+		duration: prior.Duration,
+		cpu:      prior.CPU,
+		resumed:  true,
+	}
+	switch {
+	case prior.Skipped:
+		o.err = &skipError{prior.Message}
+	case prior.Warning:
+		o.err = &warningError{prior.Message}
+	case !prior.Passed:
+		o.err = &TestFailure{Reason: prior.Reason, Message: prior.Message}
+	}
+	return o, true
+}
+
+func (s *RunState) record(index int, outcome TestOutcome) {
+	s.Completed[index] = outcome
+	if err := s.save(); err != nil {
+		reportHarnessError(TelemetryReporter, err)
+		fmt.Printf("⚠️ Stav běhu nelze uložit: %v\n", err)
+	}
+}
+
+func (s *RunState) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *RunState) remove() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
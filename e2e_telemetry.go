@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// telemetryEnv opts in to harness telemetry. It is an environment variable
+// rather than a flag so that flag parsing errors can be reported too.
+const telemetryEnv = "ABLE2FLOW_TELEMETRY_URL"
+
+// Harness error kinds sent as telemetry.
+const (
+	TelemetryPanic    = "panic"
+	TelemetryReporter = "reporter"
+	TelemetryConfig   = "config"
+)
+
+// telemetryEvent is one harness-level error. It carries no URLs, paths,
+// hostnames or test data, only what is needed to count tool failures.
+type telemetryEvent struct {
+	Kind      string   `json:"kind"`
+	Error     string   `json:"error"`
+	Frames    []string `json:"frames,omitempty"`
+	OS        string   `json:"os"`
+	Arch      string   `json:"arch"`
+	GoVersion string   `json:"go_version"`
+	At        string   `json:"at"`
+}
+
+var (
+	urlScrubRe   = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"']+`)
+	pathScrubRe  = regexp.MustCompile(`(?:[A-Za-z]:)?(?:[/\\][^\s/\\:"']+){2,}`)
+	emailScrubRe = regexp.MustCompile(`[^\s@"']+@[^\s@"']+\.[a-zA-Z]+`)
+	hostScrubRe  = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?::\d+)?\b|\blocalhost(?::\d+)?\b`)
+)
+
+// anonymize strips URLs, paths, e-mails and hosts from an error message.
+func anonymize(message string) string {
+	message = urlScrubRe.ReplaceAllString(message, "<url>")
+	message = emailScrubRe.ReplaceAllString(message, "<email>")
+	message = pathScrubRe.ReplaceAllString(message, "<path>")
+	message = hostScrubRe.ReplaceAllString(message, "<host>")
+	if r := []rune(message); len(r) > 300 {
+		message = string(r[:300]) + "…"
+	}
+	return message
+}
+
+// panicFrames returns the harness functions on the current stack, innermost
+// first, without file paths or arguments.
+func panicFrames() []string {
+	var frames []string
+	for _, line := range strings.Split(string(debug.Stack()), "\n") {
+		if !strings.HasPrefix(line, "main.") {
+			continue
+		}
+		name, _, _ := strings.Cut(line, "(")
+		if name == "main.panicFrames" || name == "main.recoverTest" || strings.HasPrefix(name, "main.main.func") {
+			continue
+		}
+		frames = append(frames, name)
+		if len(frames) == 5 {
+			break
+		}
+	}
+	return frames
+}
+
+// reportHarnessError sends one event when telemetry is enabled. Failures of
+// telemetry itself are ignored, it must never affect the run.
+func reportHarnessError(kind string, err error, frames ...string) {
+	endpoint := os.Getenv(telemetryEnv)
+	if endpoint == "" || err == nil || errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	event := telemetryEvent{
+		Kind:      kind,
+		Error:     anonymize(err.Error()),
+		Frames:    frames,
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		GoVersion: runtime.Version(),
+		At:        time.Now().UTC().Format(time.RFC3339),
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+// recoverTest turns a panicking test into a failure instead of crashing the
+// whole run, and reports it as a harness error.
+func recoverTest(name string, err *error) {
+	if r := recover(); r != nil {
+		frames := panicFrames()
+		*err = failf(ReasonHarness, "%s: panic v harnessu: %v", name, r)
+		reportHarnessError(TelemetryPanic, fmt.Errorf("%v", r), frames...)
+	}
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerTiming is one metric from a Server-Timing response header.
+type ServerTiming struct {
+	Name     string
+	Duration time.Duration
+	Desc     string
+}
+
+// parseServerTiming parses Server-Timing header values, e.g.
+// `db;dur=53.2, app;dur=47;desc="render"`. Metrics without dur are kept with
+// a zero duration; malformed parameters are ignored.
+func parseServerTiming(values []string) []ServerTiming {
+	var timings []ServerTiming
+	for _, value := range values {
+		for _, entry := range splitQuoted(value, ',') {
+			parts := splitQuoted(entry, ';')
+			name := strings.TrimSpace(parts[0])
+			if name == "" {
+				continue
+			}
+			timing := ServerTiming{Name: name}
+			for _, param := range parts[1:] {
+				key, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+				val = unquote(strings.TrimSpace(val))
+				switch strings.ToLower(strings.TrimSpace(key)) {
+				case "dur":
+					if ms, err := strconv.ParseFloat(val, 64); err == nil {
+						timing.Duration = time.Duration(ms * float64(time.Millisecond))
+					}
+				case "desc":
+					timing.Desc = val
+				}
+			}
+			timings = append(timings, timing)
+		}
+	}
+	return timings
+}
+
+// splitQuoted splits s at sep outside of double-quoted strings, so a desc
+// like "db, cache" stays in one piece.
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quoted && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && s[i] == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unquote returns the content of a quoted-string parameter value.
+func unquote(val string) string {
+	if len(val) < 2 || val[0] != '"' || val[len(val)-1] != '"' {
+		return val
+	}
+	var b strings.Builder
+	for i := 1; i < len(val)-1; i++ {
+		if val[i] == '\\' && i+1 < len(val)-1 {
+			i++
+		}
+		b.WriteByte(val[i])
+	}
+	return b.String()
+}
+
+// serverTime is the backend-reported time of one response: the "total"
+// metric when present, otherwise the longest phase. Phases are often nested
+// (db inside app), so summing them would count time twice.
+func serverTime(timings []ServerTiming) time.Duration {
+	var longest time.Duration
+	for _, t := range timings {
+		if t.Name == "total" {
+			return t.Duration
+		}
+		longest = max(longest, t.Duration)
+	}
+	return longest
+}
+
+// TimingMetrics collects round-trip times and Server-Timing phases of every
+// response seen by the harness client. It is shared by all tests of a run,
+// so durations go into histograms whose size does not grow with load.
+type TimingMetrics struct {
+	mu         sync.Mutex
+	requests   int
+	withHeader int
+	roundTrip  durationHistogram
+	server     durationHistogram
+	network    durationHistogram
+	phases     map[string]*durationHistogram
+	endpoints  map[string]*endpointCalls
+}
+
+type endpointCalls struct {
+	calls       int
+	errors      int
+	clientError int
+	durations   durationHistogram
+}
+
+func newTimingMetrics() *TimingMetrics {
+	return &TimingMetrics{phases: map[string]*durationHistogram{}, endpoints: map[string]*endpointCalls{}}
+}
+
+// recordCall counts one call of an endpoint for the scorecard. Transport
+// errors and 5xx responses are errors; 4xx are counted separately.
+func (m *TimingMetrics) recordCall(endpoint string, status int, failed bool, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.endpoints[endpoint]
+	if e == nil {
+		e = &endpointCalls{}
+		m.endpoints[endpoint] = e
+	}
+	e.calls++
+	e.durations.add(d)
+	switch {
+	case failed || status >= 500:
+		e.errors++
+	case status >= 400:
+		e.clientError++
+	}
+}
+
+func (m *TimingMetrics) record(roundTrip time.Duration, timings []ServerTiming) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests++
+	if len(timings) == 0 {
+		return
+	}
+	m.withHeader++
+	m.roundTrip.add(roundTrip)
+	server := serverTime(timings)
+	m.server.add(server)
+	m.network.add(max(roundTrip-server, 0))
+	for _, t := range timings {
+		h := m.phases[t.Name]
+		if h == nil {
+			h = &durationHistogram{}
+			m.phases[t.Name] = h
+		}
+		h.add(t.Duration)
+	}
+}
+
+// timingTransport measures the time to response headers of every request
+// and records the Server-Timing metrics the backend reports with it.
+// Every call is also counted per endpoint for the scorecard.
+type timingTransport struct {
+	next     http.RoundTripper
+	metrics  *TimingMetrics
+	backends []string
+}
+
+func (t *timingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+	endpoint := endpointKey(req, t.backends)
+	if err != nil {
+		t.metrics.recordCall(endpoint, 0, true, elapsed)
+		return resp, err
+	}
+	t.metrics.recordCall(endpoint, resp.StatusCode, false, elapsed)
+	t.metrics.record(elapsed, parseServerTiming(resp.Header.Values("Server-Timing")))
+	return resp, nil
+}
+
+// endpointKey groups calls by method and path with numeric and UUID path
+// segments replaced by {id}. Calls to hosts other than the backend and its
+// services keep their host.
+func endpointKey(req *http.Request, backends []string) string {
+	segments := strings.Split(req.URL.Path, "/")
+	for i, seg := range segments {
+		if isIDSegment(seg) {
+			segments[i] = "{id}"
+		}
+	}
+	path := strings.Join(segments, "/")
+	if path == "" {
+		path = "/"
+	}
+	if !matchesBase(req.URL.String(), backends) {
+		path = req.URL.Host + path
+	}
+	return req.Method + " " + path
+}
+
+func isIDSegment(seg string) bool {
+	if seg == "" {
+		return false
+	}
+	if _, err := strconv.Atoi(seg); err == nil {
+		return true
+	}
+	return len(seg) == 36 && strings.Count(seg, "-") == 4
+}
+
+// EndpointScore is one row of the per-endpoint scorecard.
+type EndpointScore struct {
+	Endpoint     string
+	Calls        int
+	Errors       int
+	ClientErrors int
+	P95          time.Duration
+}
+
+// scorecard returns the per-endpoint view of every call made during the run,
+// worst error rate first.
+func (m *TimingMetrics) scorecard() []EndpointScore {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var scores []EndpointScore
+	for endpoint, e := range m.endpoints {
+		scores = append(scores, EndpointScore{
+			Endpoint:     endpoint,
+			Calls:        e.calls,
+			Errors:       e.errors,
+			ClientErrors: e.clientError,
+			P95:          e.durations.percentile(0.95),
+		})
+	}
+	sortScores(scores)
+	return scores
+}
+
+// sortScores orders endpoints worst error rate first.
+func sortScores(scores []EndpointScore) {
+	sort.Slice(scores, func(i, j int) bool {
+		ri := float64(scores[i].Errors) / float64(scores[i].Calls)
+		rj := float64(scores[j].Errors) / float64(scores[j].Calls)
+		if ri != rj {
+			return ri > rj
+		}
+		return scores[i].Endpoint < scores[j].Endpoint
+	})
+}
+
+// renderScorecard formats the scorecard for the console and text reports.
+func renderScorecard(scores []EndpointScore) string {
+	if len(scores) == 0 {
+		return ""
+	}
+	out := "\n🗂️ SCORECARD ENDPOINTŮ (volání / chyby / 4xx / p95):\n"
+	for _, s := range scores {
+		icon := "✅"
+		if s.Errors > 0 {
+			icon = "❌"
+		} else if s.ClientErrors > 0 {
+			icon = "⚠️"
+		}
+		out += fmt.Sprintf("  %s %-44s %5d %5d %5d  %s\n", icon, s.Endpoint, s.Calls, s.Errors, s.ClientErrors, s.P95.Round(10*time.Microsecond))
+	}
+	return out
+}
+
+// PhaseTiming is the aggregate of one Server-Timing metric over a run.
+type PhaseTiming struct {
+	Name  string
+	Count int
+	Avg   time.Duration
+	P95   time.Duration
+}
+
+// TimingSummary separates backend-reported app time from the rest of the
+// round trip (network, proxies, queueing). Averages cover only responses
+// that carried Server-Timing.
+type TimingSummary struct {
+	Requests     int
+	WithHeader   int
+	AvgRoundTrip time.Duration
+	AvgServer    time.Duration
+	AvgNetwork   time.Duration
+	Phases       []PhaseTiming
+}
+
+func (m *TimingMetrics) summary() TimingSummary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := TimingSummary{
+		Requests:     m.requests,
+		WithHeader:   m.withHeader,
+		AvgRoundTrip: m.roundTrip.average(),
+		AvgServer:    m.server.average(),
+		AvgNetwork:   m.network.average(),
+	}
+	for name, h := range m.phases {
+		s.Phases = append(s.Phases, PhaseTiming{
+			Name:  name,
+			Count: h.count,
+			Avg:   h.average(),
+			P95:   h.percentile(0.95),
+		})
+	}
+	sort.Slice(s.Phases, func(i, j int) bool { return s.Phases[i].Name < s.Phases[j].Name })
+	return s
+}
+
+// Histogram buckets grow by histogramGrowth from histogramMin, which keeps
+// percentiles within 10% from a microsecond up to about five hours.
+const (
+	histogramMin     = time.Microsecond
+	histogramGrowth  = 1.1
+	histogramBuckets = 250
+)
+
+// durationHistogram counts durations in fixed log-spaced buckets. Count,
+// sum and max are exact; percentiles are the upper bound of their bucket.
+type durationHistogram struct {
+	counts [histogramBuckets]int
+	count  int
+	sum    time.Duration
+	max    time.Duration
+}
+
+func histogramBucket(d time.Duration) int {
+	if d <= histogramMin {
+		return 0
+	}
+	i := int(math.Ceil(math.Log(float64(d)/float64(histogramMin)) / math.Log(histogramGrowth)))
+	return min(i, histogramBuckets-1)
+}
+
+func (h *durationHistogram) add(d time.Duration) {
+	h.counts[histogramBucket(d)]++
+	h.count++
+	h.sum += d
+	h.max = max(h.max, d)
+}
+
+func (h *durationHistogram) average() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.count)
+}
+
+// percentile returns the duration at or below which the p-th share of the
+// durations lies, never more than the largest one seen.
+func (h *durationHistogram) percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := int(float64(h.count-1)*p) + 1
+	seen := 0
+	for i, n := range h.counts {
+		if seen += n; seen >= rank {
+			bound := time.Duration(float64(histogramMin) * math.Pow(histogramGrowth, float64(i)))
+			return min(bound, h.max)
+		}
+	}
+	return h.max
+}
+
+// renderTiming formats the summary for the console and text reports.
+func renderTiming(s TimingSummary) string {
+	out := "\n⏱️ SERVER TIMING:\n"
+	if s.WithHeader == 0 {
+		return out + fmt.Sprintf("  Backend neposílá Server-Timing (%d požadavků)\n", s.Requests)
+	}
+	out += fmt.Sprintf("  Požadavků: %d, se Server-Timing: %d\n", s.Requests, s.WithHeader)
+	out += fmt.Sprintf("  Průměr: celkem %s, aplikace %s, síť a ostatní %s\n",
+		s.AvgRoundTrip.Round(time.Microsecond), s.AvgServer.Round(time.Microsecond), s.AvgNetwork.Round(time.Microsecond))
+	for _, p := range s.Phases {
+		out += fmt.Sprintf("  %-16s %4dx  avg %s, p95 %s\n", p.Name, p.Count,
+			p.Avg.Round(time.Microsecond), p.P95.Round(time.Microsecond))
+	}
+	return out
+}
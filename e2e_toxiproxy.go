@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Toxic mirrors the toxiproxy API payload. Attributes depend on Type, e.g.
+// latency/jitter for "latency", rate for "bandwidth", timeout for
+// "reset_peer".
+type Toxic struct {
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Stream     string         `json:"stream,omitempty"`
+	Toxicity   float64        `json:"toxicity"`
+	Attributes map[string]int `json:"attributes"`
+}
+
+type ToxiproxyClient struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewToxiproxyClient(baseURL string, client *http.Client) *ToxiproxyClient {
+	return &ToxiproxyClient{BaseURL: baseURL, Client: client}
+}
+
+func (t *ToxiproxyClient) SetEnabled(proxy string, enabled bool) error {
+	return t.send(http.MethodPost, "/proxies/"+proxy, map[string]bool{"enabled": enabled})
+}
+
+func (t *ToxiproxyClient) AddToxic(proxy string, toxic Toxic) error {
+	if toxic.Toxicity == 0 {
+		toxic.Toxicity = 1
+	}
+	return t.send(http.MethodPost, "/proxies/"+proxy+"/toxics", toxic)
+}
+
+func (t *ToxiproxyClient) RemoveToxic(proxy, name string) error {
+	return t.send(http.MethodDelete, "/proxies/"+proxy+"/toxics/"+name, nil)
+}
+
+// Reset re-enables all proxies and removes all toxics.
+func (t *ToxiproxyClient) Reset() error {
+	return t.send(http.MethodPost, "/reset", nil)
+}
+
+func (t *ToxiproxyClient) send(method, path string, payload interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, t.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := readLimited(resp.Body, 64<<10)
+		return fmt.Errorf("toxiproxy %s %s: status %d: %s", method, path, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// withToxic runs fn while toxic is active on the DB proxy and always removes
+// it afterwards.
+func withToxic(rc *RunContext, toxic Toxic, fn func() error) error {
+	proxy := rc.Config.ToxiproxyProxy
+	if err := rc.Toxiproxy.AddToxic(proxy, toxic); err != nil {
+		return failf(ReasonConnection, "Toxic %s nelze aktivovat: %v", toxic.Name, err)
+	}
+	defer func() {
+		if err := rc.Toxiproxy.RemoveToxic(proxy, toxic.Name); err != nil {
+			fmt.Fprintf(rc.Out, "⚠️ Toxic %s nelze odstranit: %v\n", toxic.Name, err)
+		}
+	}()
+	return fn()
+}
+
+// expectGracefulDegradation requests url and accepts a success, a clean 5xx
+// answer or a client-side timeout that fires on time. A dropped connection
+// or a hang past the client timeout is not graceful.
+func expectGracefulDegradation(rc *RunContext, url string) error {
+	start := time.Now()
+	resp, err := rc.Client.Get(url)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() && elapsed <= rc.Client.Timeout+time.Second {
+			fmt.Fprintf(rc.Out, "✅ %s - čistý timeout po %s\n", url, elapsed.Round(time.Millisecond))
+			return nil
+		}
+		return requestFailure(fmt.Sprintf("%s - nečisté selhání po %s", url, elapsed.Round(time.Millisecond)), err)
+	}
+	defer resp.Body.Close()
+	discardBody(rc, resp)
+
+	if resp.StatusCode == http.StatusOK || resp.StatusCode >= 500 {
+		fmt.Fprintf(rc.Out, "✅ %s - status %d za %s\n", url, resp.StatusCode, elapsed.Round(time.Millisecond))
+		return nil
+	}
+	return failf(ReasonBadStatus, "%s - neočekávaný status %d", url, resp.StatusCode)
+}
+
+func testDBLatency(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🐢 TEST: DB Latency Degradation")
+	toxic := Toxic{Name: "e2e_latency", Type: "latency", Stream: "downstream", Attributes: map[string]int{"latency": 6000}}
+
+	return withToxic(rc, toxic, func() error {
+		return errors.Join(
+			expectDegradedHealth(rc, "latenci DB"),
+			expectGracefulDegradation(rc, rc.Config.BackendURL+"/api/tasks"),
+		)
+	})
+}
+
+// expectDegradedHealth checks that /health answers within the client timeout
+// and reports the run as degraded, either overall or by a failing component,
+// without calling the whole backend down.
+func expectDegradedHealth(rc *RunContext, cause string) error {
+	health, err := fetchHealth(rc)
+	if err != nil {
+		return fmt.Errorf("Health při %s: %w", cause, err)
+	}
+	unhealthy := health.unhealthy()
+	if health.Status == "degraded" || health.Status != "down" && len(unhealthy) > 0 {
+		fmt.Fprintf(rc.Out, "✅ Health při %s hlásí %s: %s\n", cause, health.Status, strings.Join(unhealthy, ", "))
+		return nil
+	}
+	return failf(ReasonAssertion, "Health při %s hlásí %q bez nefunkční komponenty místo degraded", cause, health.Status)
+}
+
+func testDBBandwidth(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n📉 TEST: DB Bandwidth Limit")
+	toxic := Toxic{Name: "e2e_bandwidth", Type: "bandwidth", Stream: "downstream", Attributes: map[string]int{"rate": 1}}
+
+	return withToxic(rc, toxic, func() error {
+		return expectGracefulDegradation(rc, rc.Config.BackendURL+"/api/tasks")
+	})
+}
+
+func testDBConnectionReset(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🔌 TEST: DB Connection Reset")
+	toxic := Toxic{Name: "e2e_reset", Type: "reset_peer", Stream: "downstream", Attributes: map[string]int{"timeout": 0}}
+
+	err := withToxic(rc, toxic, func() error {
+		return errors.Join(
+			expectDegradedHealth(rc, "resetu DB spojení"),
+			expectGracefulDegradation(rc, rc.Config.BackendURL+"/api/tasks"),
+		)
+	})
+
+	deadline := time.Now().Add(rc.Config.RecoveryTimeout)
+	for time.Now().Before(deadline) {
+		if probeHealth(rc) == "ok" {
+			fmt.Fprintln(rc.Out, "✅ Po odstranění toxicu backend opět OK")
+			return err
+		}
+		time.Sleep(time.Second)
+	}
+	return errors.Join(err, failf(ReasonTimeout, "Backend se po odstranění toxicu nezotavil do %s", rc.Config.RecoveryTimeout))
+}
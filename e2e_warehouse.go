@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// warehouseTokenEnv holds the bearer token for the warehouse sink, e.g. the
+// output of `gcloud auth print-access-token` for BigQuery.
+const warehouseTokenEnv = "ABLE2FLOW_WAREHOUSE_TOKEN"
+
+// Warehouse formats: BigQuery tabledata.insertAll, or newline-delimited JSON
+// posted to any HTTP ingestion endpoint (ClickHouse, Snowflake pipes, ...).
+const (
+	WarehouseBigQuery = "bigquery"
+	WarehouseNDJSON   = "ndjson"
+)
+
+// warehouseBatch keeps each request well below ingestion size limits.
+const warehouseBatch = 500
+
+// warehouseRow is one test outcome with the run context needed to join it
+// with deployment and incident data. Columns are flat, so the table schema
+// stays stable when the JSON report grows.
+type warehouseRow struct {
+	RunID      string        `json:"run_id"`
+	DeployID   string        `json:"deploy_id,omitempty"`
+	Env        string        `json:"env,omitempty"`
+	Suite      string        `json:"suite"`
+	Stage      string        `json:"stage"`
+	Shard      string        `json:"shard,omitempty"`
+	Backend    string        `json:"backend"`
+	Frontend   string        `json:"frontend"`
+	Started    string        `json:"started"`
+	Test       string        `json:"test"`
+	Status     string        `json:"status"`
+	Severity   Severity      `json:"severity"`
+	Reason     FailureReason `json:"reason,omitempty"`
+	Message    string        `json:"message,omitempty"`
+	Attempts   int           `json:"attempts"`
+	DurationMs int64         `json:"duration_ms"`
+}
+
+func parseWarehouseFormat(value string) (string, error) {
+	switch value {
+	case WarehouseBigQuery, WarehouseNDJSON:
+		return value, nil
+	}
+	return "", fmt.Errorf("neznámý formát %q (bigquery, ndjson)", value)
+}
+
+func warehouseRows(cfg Config, results TestResult) []warehouseRow {
+	rows := make([]warehouseRow, 0, len(results.Outcomes))
+	for _, o := range results.Outcomes {
+		t := reportTest(o)
+		row := warehouseRow{
+			RunID:      results.RunID,
+			DeployID:   cfg.DeployID,
+			Env:        cfg.Env,
+			Suite:      o.Suite,
+			Stage:      suiteName(cfg),
+			Backend:    cfg.BackendURL,
+			Frontend:   cfg.FrontendURL,
+			Started:    results.Started.UTC().Format(time.RFC3339),
+			Test:       t.Name,
+			Status:     t.Status,
+			Severity:   t.Severity,
+			Reason:     t.Reason,
+			Message:    t.Message,
+			Attempts:   t.Attempts,
+			DurationMs: t.DurationMs,
+		}
+		if cfg.Shard.Total > 0 {
+			row.Shard = cfg.Shard.String()
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// encodeWarehouseBatch builds the request body for rows. BigQuery rows carry
+// an insertId, so a retried push of the same run does not duplicate them.
+func encodeWarehouseBatch(format string, rows []warehouseRow) ([]byte, string, error) {
+	if format == WarehouseBigQuery {
+		type insertRow struct {
+			InsertID string       `json:"insertId"`
+			JSON     warehouseRow `json:"json"`
+		}
+		req := struct {
+			SkipInvalidRows bool        `json:"skipInvalidRows"`
+			Rows            []insertRow `json:"rows"`
+		}{}
+		for _, row := range rows {
+			req.Rows = append(req.Rows, insertRow{row.RunID + "/" + row.Test, row})
+		}
+		data, err := json.Marshal(req)
+		return data, "application/json", err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return nil, "", err
+		}
+	}
+	return buf.Bytes(), "application/x-ndjson", nil
+}
+
+// pushWarehouse sends rows in batches. BigQuery answers 200 even when some
+// rows were rejected, so its insertErrors are checked too.
+func pushWarehouse(cfg Config, rows []warehouseRow) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	token := os.Getenv(warehouseTokenEnv)
+	for start := 0; start < len(rows); start += warehouseBatch {
+		batch := rows[start:min(start+warehouseBatch, len(rows))]
+		body, contentType, err := encodeWarehouseBatch(cfg.WarehouseFormat, batch)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequest(http.MethodPost, cfg.WarehouseURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", contentType)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		data, err := readLimited(resp.Body, 1<<20)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("status %d: %s", resp.StatusCode, anonymize(string(data)))
+		}
+		if cfg.WarehouseFormat == WarehouseBigQuery {
+			var result struct {
+				InsertErrors []json.RawMessage `json:"insertErrors"`
+			}
+			json.Unmarshal(data, &result)
+			if n := len(result.InsertErrors); n > 0 {
+				return fmt.Errorf("BigQuery odmítl %d/%d řádků: %s", n, len(batch), result.InsertErrors[0])
+			}
+		}
+	}
+	return nil
+}
+
+// saveWarehouse streams the run into the warehouse. The sink is for
+// analytics only; a failed push is reported but never fails the run.
+func saveWarehouse(cfg Config, results TestResult) {
+	rows := warehouseRows(cfg, results)
+	if err := pushWarehouse(cfg, rows); err != nil {
+		reportHarnessError(TelemetryReporter, err)
+		fmt.Printf("⚠️ Výsledky nelze odeslat do warehouse: %v\n", err)
+		return
+	}
+	fmt.Printf("🏛️ Výsledky odeslány do warehouse (%s, %d řádků)\n", cfg.WarehouseFormat, len(rows))
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket opcodes of RFC 6455.
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xA
+)
+
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsPingInterval is how often every connection is pinged while held open.
+const wsPingInterval = time.Second
+
+// wsConn is a minimal client side of a WebSocket connection, enough to keep
+// it alive and receive broadcasts. Frames are read by one goroutine.
+type wsConn struct {
+	rw       io.ReadWriteCloser
+	r        *bufio.Reader
+	limit    int64
+	mu       sync.Mutex
+	messages chan wsMessage
+	pongs    chan struct{}
+	done     chan struct{}
+	err      error
+}
+
+// wsMessage is one received data message with its arrival time.
+type wsMessage struct {
+	data []byte
+	at   time.Time
+}
+
+// dialWebSocket upgrades a GET of target through the harness client, so the
+// handshake carries the same auth as every other backend call.
+func dialWebSocket(ctx context.Context, rc *RunContext, target string) (*wsConn, error) {
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	// The client timeout would also cut the upgraded connection; ctx bounds
+	// the whole probe instead.
+	client := *rc.Client
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, requestFailure("WebSocket handshake", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		discardBody(rc, resp)
+		resp.Body.Close()
+		return nil, failf(ReasonBadStatus, "WebSocket handshake: status %d, očekáváno 101", resp.StatusCode)
+	}
+	rw, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, failf(ReasonHarness, "WebSocket handshake: spojení nelze převzít")
+	}
+	sum := sha1.Sum([]byte(key + wsGUID))
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != base64.StdEncoding.EncodeToString(sum[:]) {
+		rw.Close()
+		return nil, failf(ReasonAssertion, "WebSocket handshake: neplatný Sec-WebSocket-Accept %q", accept)
+	}
+
+	c := &wsConn{
+		rw:       rw,
+		r:        bufio.NewReader(rw),
+		limit:    rc.Config.MaxBodyBytes,
+		messages: make(chan wsMessage, 16),
+		pongs:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// writeFrame sends one masked frame, as required from clients.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, 0x80|byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 0x80|126, byte(n>>8), byte(n))
+	default:
+		header = binary.BigEndian.AppendUint64(append(header, 0x80|127), uint64(n))
+	}
+	mask := make([]byte, 4)
+	rand.Read(mask)
+	masked := make([]byte, len(payload))
+	for i, b := range payload {
+		masked[i] = b ^ mask[i%4]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.rw.Write(append(append(header, mask...), masked...))
+	return err
+}
+
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(c.r, head[:]); err != nil {
+		return
+	}
+	fin, opcode = head[0]&0x80 != 0, head[0]&0x0F
+	n := uint64(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.r, ext[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.r, ext[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > uint64(c.limit) {
+		return fin, opcode, nil, fmt.Errorf("%w %d B", errBodyTooLarge, c.limit)
+	}
+	// Servers must not mask, but a masked frame is still readable.
+	var mask [4]byte
+	if head[1]&0x80 != 0 {
+		if _, err = io.ReadFull(c.r, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(c.r, payload); err != nil {
+		return
+	}
+	if head[1]&0x80 != 0 {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+func (c *wsConn) readLoop() {
+	defer close(c.done)
+	var message []byte
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			c.err = err
+			return
+		}
+		switch opcode {
+		case wsPing:
+			c.writeFrame(wsPong, payload)
+		case wsPong:
+			select {
+			case c.pongs <- struct{}{}:
+			default:
+			}
+		case wsClose:
+			c.err = fmt.Errorf("server spojení zavřel")
+			return
+		case wsText, wsBinary, wsContinuation:
+			message = append(message, payload...)
+			if !fin {
+				continue
+			}
+			select {
+			case c.messages <- wsMessage{message, time.Now()}:
+			default:
+				// Nobody waits for unrelated broadcasts; drop them.
+			}
+			message = nil
+		}
+	}
+}
+
+func (c *wsConn) close() {
+	c.writeFrame(wsClose, []byte{0x03, 0xE8})
+	c.rw.Close()
+	<-c.done
+}
+
+// wsURL returns the WebSocket endpoint for a backend base URL.
+func wsURL(base, path string) string {
+	return strings.TrimSuffix(base, "/") + path
+}
+
+// pingAll pings every connection and waits for all pongs within timeout.
+func pingAll(conns []*wsConn, timeout time.Duration) error {
+	var errs []error
+	for i, c := range conns {
+		if err := c.writeFrame(wsPing, []byte("e2e")); err != nil {
+			errs = append(errs, fmt.Errorf("spojení %d: ping: %w", i+1, err))
+		}
+	}
+	deadline := time.After(timeout)
+	for i, c := range conns {
+		select {
+		case <-c.pongs:
+		case <-c.done:
+			errs = append(errs, fmt.Errorf("spojení %d: %w", i+1, c.err))
+		case <-deadline:
+			return errors.Join(append(errs, failf(ReasonTimeout, "spojení %d: pong nepřišel do %s", i+1, timeout))...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// testWebSocketScale opens -ws-connections concurrent connections, keeps
+// them alive with pings for -ws-hold and checks that one broadcast
+// notification reaches all of them within -ws-latency.
+func testWebSocketScale(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🔌 TEST: WebSocket Scale")
+	cfg := rc.Config
+	target := wsURL(cfg.BackendURL, cfg.WSPath)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.WSHold+cfg.WSLatency+2*cfg.RequestTimeout)
+	defer cancel()
+
+	conns := make([]*wsConn, cfg.WSConnections)
+	dialErrs := make([]error, cfg.WSConnections)
+	var wg sync.WaitGroup
+	for i := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conns[i], dialErrs[i] = dialWebSocket(ctx, rc, target)
+		}()
+	}
+	wg.Wait()
+	defer func() {
+		for _, c := range conns {
+			if c != nil {
+				c.close()
+			}
+		}
+	}()
+	var failed []error
+	for i, err := range dialErrs {
+		if err != nil {
+			failed = append(failed, fmt.Errorf("spojení %d: %w", i+1, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("Otevřeno %d/%d spojení na %s: %w", len(conns)-len(failed), len(conns), target, failed[0])
+	}
+	fmt.Fprintf(rc.Out, "   Otevřeno %d spojení na %s\n", len(conns), target)
+
+	for held := time.Duration(0); held < cfg.WSHold; held += wsPingInterval {
+		if err := pingAll(conns, wsPingInterval+cfg.RequestTimeout); err != nil {
+			return fmt.Errorf("Keep-alive po %s: %w", held, err)
+		}
+		time.Sleep(wsPingInterval)
+	}
+	fmt.Fprintf(rc.Out, "   Všechna spojení drží %s (ping každých %s)\n", cfg.WSHold, wsPingInterval)
+
+	// Drop anything broadcast while holding, so only the new one counts.
+	for _, c := range conns {
+		for len(c.messages) > 0 {
+			<-c.messages
+		}
+	}
+	sent := time.Now()
+	var created struct {
+		ID interface{} `json:"id"`
+	}
+	if _, err := postJSON(rc, "/api/notifications/test/create-sample", nil, &created); err != nil {
+		return fmt.Errorf("Broadcast notifikace: %w", err)
+	}
+	if created.ID == nil {
+		return failf(ReasonSchema, "Broadcast notifikace: odpověď neobsahuje id")
+	}
+	marker, _ := json.Marshal(created.ID)
+
+	timer := time.NewTimer(time.Until(sent.Add(cfg.WSLatency)))
+	defer timer.Stop()
+	expired := false
+	var latencies []time.Duration
+	var missing []int
+	for i, c := range conns {
+		at, ok := awaitBroadcast(c, marker, timer.C, &expired)
+		if !ok || at.Sub(sent) > cfg.WSLatency {
+			missing = append(missing, i+1)
+			continue
+		}
+		latencies = append(latencies, at.Sub(sent))
+	}
+	if len(missing) > 0 {
+		return failf(ReasonTimeout, "Notifikace %s nedorazila do %s na %d/%d spojení (např. spojení %d)", marker, cfg.WSLatency, len(missing), len(conns), missing[0])
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	fmt.Fprintf(rc.Out, "✅ Notifikace %s dorazila na všech %d spojení (p50 %s, max %s, limit %s)\n",
+		marker, len(conns), latencies[len(latencies)/2].Round(time.Millisecond), latencies[len(latencies)-1].Round(time.Millisecond), cfg.WSLatency)
+	return nil
+}
+
+// isBroadcast reports whether a message is the notification with the JSON id
+// marker, sent either bare or wrapped in a "notification" or "data" envelope.
+func isBroadcast(data, marker []byte) bool {
+	type ided struct {
+		ID json.RawMessage `json:"id"`
+	}
+	var msg struct {
+		ided
+		Notification ided `json:"notification"`
+		Data         ided `json:"data"`
+	}
+	if json.Unmarshal(data, &msg) != nil {
+		return false
+	}
+	for _, id := range []json.RawMessage{msg.ID, msg.Notification.ID, msg.Data.ID} {
+		if id != nil && bytes.Equal(bytes.TrimSpace(id), marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// awaitBroadcast waits for the message with the id marker on c. Once the
+// deadline has passed or the connection dropped, only messages already
+// received are checked.
+func awaitBroadcast(c *wsConn, marker []byte, deadline <-chan time.Time, expired *bool) (time.Time, bool) {
+	for !*expired {
+		select {
+		case msg := <-c.messages:
+			if isBroadcast(msg.data, marker) {
+				return msg.at, true
+			}
+		case <-c.done:
+			return receivedBroadcast(c, marker)
+		case <-deadline:
+			*expired = true
+		}
+	}
+	return receivedBroadcast(c, marker)
+}
+
+func receivedBroadcast(c *wsConn, marker []byte) (time.Time, bool) {
+	for {
+		select {
+		case msg := <-c.messages:
+			if isBroadcast(msg.data, marker) {
+				return msg.at, true
+			}
+		default:
+			return time.Time{}, false
+		}
+	}
+}
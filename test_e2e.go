@@ -1,304 +1,1265 @@
 package main
 
 import (
+	"bytes"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
-type HealthResponse struct {
-	Status string `json:"status"`
+type Config struct {
+	BackendURL string
+	// Services are base URLs of backend services that run apart from
+	// BackendURL, keyed by service name.
+	Services         map[string]string
+	Matrix           testMatrix
+	FrontendURL      string
+	Chaos            []string
+	BackendContainer string
+	ComposeFile      string
+	ComposeBackend   string
+	ComposeDB        string
+	ToxiproxyURL     string
+	ToxiproxyProxy   string
+	RequestTimeout   time.Duration
+	ReportPath       string
+	Repro            bool
+	JSONReportPath   string
+	Parallel         int
+	Retries          int
+	MaxBodyBytes     int64
+	RecoveryTimeout  time.Duration
+	BackupEndpoint   string
+	BackupCmd        string
+	RestoreCmd       string
+	RestoreURL       string
+	SearchPath       string
+	AdminPath        string
+	ExpiryGrace      time.Duration
+	StaleTokenTTL    time.Duration
+	TestClockPath    string
+	AvatarPath       string
+	FlagsPath        string
+	MailCatcherURL   string
+	MailTo           string
+	ExportPath       string
+	ImportPath       string
+	CSVImportPath    string
+	JobDeadline      time.Duration
+	WSPath           string
+	DependencyToggle string
+	WSConnections    int
+	WSHold           time.Duration
+	WSLatency        time.Duration
+	ClockSkewWarn    time.Duration
+	ClockSkewFail    time.Duration
+	Shard            Shard
+	SignKey          ed25519.PrivateKey
+	Journeys         string
+	LoadDuration     time.Duration
+	LoadWorkers      int
+	LoadMaxErrorRate float64
+	// Load snapshots are written every interval to LoadSnapshotPath, or to
+	// a new file in StateDir when it is empty.
+	LoadSnapshotPath     string
+	LoadSnapshotInterval time.Duration
+	Smoke                bool
+	FailOn               Severity
+	ConfigPath           string
+	Env                  string
+	Auth                 AuthConfig
+	Roles                map[string]AuthConfig
+	Location             *time.Location
+	StateDir             string
+	ResumeID             string
+	// RunID names a new run; empty mints one. Shards of one CI run share
+	// it, and the daemon sets it so its callback matches the stored results.
+	RunID         string
+	Args          []string
+	AssetManifest string
+	FrontendDist  string
+	// Budgets maps suite name (smoke, functional) to its time budget.
+	Budgets    map[string]time.Duration
+	BudgetPath string
+	TimingFile string
+	// Results are streamed to WarehouseURL when set; DeployID tags the rows
+	// for joining with deployment data.
+	WarehouseURL    string
+	WarehouseFormat string
+	DeployID        string
+	// CostReportPath receives the run time attribution; GateTarget is the
+	// duration the smoke gate should fit into.
+	CostReportPath string
+	GateTarget     time.Duration
+}
+
+type RunContext struct {
+	Config    Config
+	Client    *http.Client
+	Toxiproxy *ToxiproxyClient
+	Auth      AuthProvider
+	// Roles holds a client per profile role, authenticated as that role.
+	Roles     map[string]*http.Client
+	Timing    *TimingMetrics
+	Endpoints Endpoints
+	Deadline  time.Time
+	Out       io.Writer
+	// Params are the matrix parameters of the running test instance.
+	Params map[string]string
+}
+
+// testCase is one functional test. Smoke tests form the post-deploy gate
+// selected by -smoke.
+type testCase struct {
+	name     string
+	fn       func(rc *RunContext) error
+	smoke    bool
+	severity Severity
+	// service is the split-out backend service the test exercises; it
+	// runs against that service's base URL when one is declared.
+	service string
+	// suite is the block the test was registered in; the cost report
+	// groups by it.
+	suite string
+}
+
+// Suites of buildTests. Opt-in tests run only when their flag is set;
+// SuiteHarness holds chaos actions and run-level checks.
+const (
+	SuiteCore     = "core"
+	SuiteJourneys = "journeys"
+	SuiteDBFaults = "db-faults"
+	SuiteOptIn    = "opt-in"
+	SuiteAdmin    = "admin"
+	SuiteHarness  = "harness"
+)
+
+// TestOutcome is the final state of one test or chaos action in plan order.
+type TestOutcome struct {
+	Name     string
+	Suite    string
+	Passed   bool
+	Skipped  bool
+	Warning  bool
+	Severity Severity
+	Reason   FailureReason
+	Message  string
+	Attempts int
+	Duration time.Duration
+	// CPU is the harness CPU time spent while the test ran.
+	CPU time.Duration
+	// Repro is the path of the curl script replaying a failed test.
+	Repro string
 }
 
 type TestResult struct {
-	Passed []string
-	Failed []string
+	Passed    []string
+	Failed    []string
+	Flaky     []string
+	Skipped   []string
+	Warnings  []string
+	Outcomes  []TestOutcome
+	Timing    TimingSummary
+	Scorecard []EndpointScore
+	Started   time.Time
+	RunID     string
+	// FeatureFlags is the flag snapshot the tests were gated on.
+	FeatureFlags map[string]bool
 }
 
-func testBackendHealth() bool {
-	fmt.Println("\n📡 TEST 1: Backend Health Check")
-	client := &http.Client{Timeout: 5 * time.Second}
+// fetchJSON GETs url and decodes a 200 response into v.
+func fetchJSON(rc *RunContext, url string, v interface{}) (int, error) {
+	resp, err := rc.Client.Get(url)
+	if err != nil {
+		return 0, requestFailure("GET "+url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, failf(ReasonBadStatus, "GET %s: status %d", url, resp.StatusCode)
+	}
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return resp.StatusCode, requestFailure("GET "+url, err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return resp.StatusCode, failf(ReasonSchema, "GET %s: neplatný JSON: %v", url, err)
+	}
+	return resp.StatusCode, nil
+}
 
-	resp, err := client.Get("http://localhost:8000/health")
+// postJSON POSTs payload to a backend path and decodes a 2xx response into v.
+// A 202 with a Location is followed as an async job and v gets its result.
+func postJSON(rc *RunContext, path string, payload, v interface{}) (int, error) {
+	body, _ := json.Marshal(payload)
+	resp, err := rc.Client.Post(rc.Config.BackendURL+path, "application/json", bytes.NewReader(body))
 	if err != nil {
-		fmt.Printf("❌ Backend health check - endpoint nedostupný: %v\n", err)
-		return false
+		return 0, requestFailure("POST "+path, err)
 	}
 	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, failf(ReasonBadStatus, "POST %s: status %d", path, resp.StatusCode)
+	}
+	data, err := readBody(rc, resp)
+	if err != nil {
+		return resp.StatusCode, requestFailure("POST "+path, err)
+	}
+	if resp.StatusCode == http.StatusAccepted && resp.Header.Get("Location") != "" {
+		statusURL, err := jobLocation(rc, "POST "+path, resp, data, "")
+		if err != nil {
+			return resp.StatusCode, err
+		}
+		_, err = followJob(rc, statusURL, v)
+		return resp.StatusCode, err
+	}
+	if v != nil {
+		if err := json.Unmarshal(data, v); err != nil {
+			return resp.StatusCode, failf(ReasonSchema, "POST %s: neplatný JSON: %v", path, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// createTask creates a task through the API and returns its ID.
+func createTask(rc *RunContext, title, description string) (int, error) {
+	return createTaskWith(rc, map[string]string{"title": title, "description": description})
+}
 
-	var health HealthResponse
-	if err := json.Unmarshal(body, &health); err != nil {
-		fmt.Printf("❌ Backend health check - neplatný JSON: %v\n", err)
-		return false
+// createTaskWith creates a task from arbitrary fields, e.g. with a due_date.
+func createTaskWith(rc *RunContext, fields map[string]string) (int, error) {
+	payload, _ := json.Marshal(fields)
+	resp, err := rc.Client.Post(rc.Config.BackendURL+"/api/tasks", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return 0, requestFailure("POST /api/tasks", err)
 	}
+	defer resp.Body.Close()
 
-	if health.Status == "ok" {
-		fmt.Printf("✅ Backend health check - status OK\n")
-		fmt.Printf("   Response: %s\n", string(body))
-		return true
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return 0, failf(ReasonBadStatus, "POST /api/tasks: status %d", resp.StatusCode)
 	}
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return 0, requestFailure("POST /api/tasks", err)
+	}
+
+	var task struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(body, &task); err != nil {
+		return 0, failf(ReasonSchema, "POST /api/tasks: neplatný JSON: %v", err)
+	}
+	return task.ID, nil
+}
 
-	fmt.Printf("❌ Backend health check - status není OK\n")
-	return false
+func deleteTask(rc *RunContext, id int) {
+	req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/tasks/%d", rc.Config.BackendURL, id), nil)
+	resp, err := rc.Client.Do(req)
+	if err != nil {
+		fmt.Fprintf(rc.Out, "⚠️ Úklid tasku %d selhal: %v\n", id, err)
+		return
+	}
+	resp.Body.Close()
 }
 
-func testFrontendAvailability() bool {
-	fmt.Println("\n🏠 TEST 2: Frontend Landing Page")
-	client := &http.Client{Timeout: 5 * time.Second}
+func testBackendHealth(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n📡 TEST 1: Backend Health Check")
 
-	resp, err := client.Get("http://localhost:5173")
+	// Every declared service has its own health endpoint; one failing or
+	// degraded service decides the outcome like the backend itself.
+	var failures []error
+	var warnings []string
+	for i, base := range rc.Config.backendURLs() {
+		label := "Backend"
+		if i > 0 {
+			label = "Služba " + serviceNames(rc.Config.Services)[i-1]
+		}
+		err := checkHealth(rc, label, base)
+		switch {
+		case warned(err):
+			warnings = append(warnings, err.Error())
+		case err != nil:
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Join(failures...)
+	}
+	if len(warnings) > 0 {
+		return &warningError{strings.Join(warnings, "; ")}
+	}
+	return nil
+}
+
+// checkHealth checks the /health endpoint of one backend or service base URL.
+func checkHealth(rc *RunContext, label, base string) error {
+	resp, err := rc.Client.Get(base + "/health")
 	if err != nil {
-		fmt.Printf("❌ Frontend landing page - nedostupný: %v\n", err)
-		return false
+		return requestFailure(label+" health check - endpoint nedostupný", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == 200 {
-		fmt.Printf("✅ Frontend landing page načten\n")
-		fmt.Printf("   Status code: %d\n", resp.StatusCode)
-		fmt.Printf("   Content-Type: %s\n", resp.Header.Get("Content-Type"))
-		return true
+	// A down backend may still describe its components in a 503.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return failf(ReasonBadStatus, "%s health check - status %d", label, resp.StatusCode)
 	}
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return requestFailure(label+" health check", err)
+	}
+
+	var health HealthResponse
+	if err := json.Unmarshal(body, &health); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return failf(ReasonBadStatus, "%s health check - status %d", label, resp.StatusCode)
+		}
+		return failf(ReasonSchema, "%s health check - neplatný JSON: %v", label, err)
+	}
+	unhealthy := strings.Join(health.unhealthy(), ", ")
 
-	fmt.Printf("❌ Frontend landing page - neočekávaný status: %d\n", resp.StatusCode)
-	return false
+	switch {
+	case health.Status == "ok" && resp.StatusCode == http.StatusOK:
+		fmt.Fprintf(rc.Out, "✅ %s health check - status OK\n", label)
+		fmt.Fprintf(rc.Out, "   Response: %s\n", string(body))
+		return nil
+	case health.Status == "degraded" && resp.StatusCode == http.StatusOK:
+		return &warningError{fmt.Sprintf("%s health check - degraded: %s", label, unhealthy)}
+	case health.Status == "down" && resp.StatusCode != http.StatusOK:
+		return failf(ReasonBadStatus, "%s health check - down (status %d): %s", label, resp.StatusCode, unhealthy)
+	case health.Status == "down":
+		return failf(ReasonAssertion, "%s health check - down: %s", label, unhealthy)
+	}
+	return failf(ReasonAssertion, "%s health check - neočekávaný stav %q (status %d)", label, health.Status, resp.StatusCode)
 }
 
-func testMarketplaceAPI() bool {
-	fmt.Println("\n🎯 TEST 3: Marketplace API")
-	client := &http.Client{Timeout: 5 * time.Second}
+func testFrontendAvailability(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🏠 TEST 2: Frontend Landing Page")
+	client := rc.Client
 
-	endpoints := []string{
-		"http://localhost:8000/api/tasks",
-		"http://localhost:8000/tasks",
-		"http://localhost:8000/api/marketplace",
-		"http://localhost:8000/marketplace",
+	resp, err := client.Get(rc.Config.FrontendURL)
+	if err != nil {
+		return requestFailure("Frontend landing page - nedostupný", err)
 	}
+	defer resp.Body.Close()
 
-	for _, endpoint := range endpoints {
-		resp, err := client.Get(endpoint)
+	if resp.StatusCode == 200 {
+		sum, size, err := hashBody(rc, resp)
 		if err != nil {
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == 200 {
-			body, _ := io.ReadAll(resp.Body)
-			var data []map[string]interface{}
-			if err := json.Unmarshal(body, &data); err == nil {
-				fmt.Printf("✅ Marketplace API dostupné na: %s\n", endpoint)
-				fmt.Printf("   Počet tasků: %d\n", len(data))
-				if len(data) > 0 {
-					if title, ok := data[0]["title"].(string); ok {
-						fmt.Printf("   První task: %s\n", title)
-					}
-				}
-				return true
-			}
+			return requestFailure("Frontend landing page", err)
 		}
+		fmt.Fprintf(rc.Out, "✅ Frontend landing page načten\n")
+		fmt.Fprintf(rc.Out, "   Status code: %d\n", resp.StatusCode)
+		fmt.Fprintf(rc.Out, "   Content-Type: %s\n", resp.Header.Get("Content-Type"))
+		fmt.Fprintf(rc.Out, "   Velikost: %d B, SHA-256: %s\n", size, sum)
+		return nil
 	}
 
-	fmt.Println("❌ Marketplace API - žádný endpoint nenalezen")
-	return false
+	return failf(ReasonBadStatus, "Frontend landing page - neočekávaný status: %d", resp.StatusCode)
 }
 
-func testNotificationCreation() bool {
-	fmt.Println("\n🔔 TEST 4: Notification Creation")
-	client := &http.Client{Timeout: 5 * time.Second}
+func testMarketplaceAPI(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🎯 TEST 3: Marketplace API")
 
-	resp, err := client.Get("http://localhost:8000/api/notifications/test/create-sample")
+	endpoint, err := rc.endpoint(EndpointMarketplace)
 	if err != nil {
-		fmt.Printf("❌ Notification creation - selhala: %v\n", err)
-		return false
+		return fmt.Errorf("Marketplace API - %w", err)
+	}
+	endpoint = rc.paramURL(endpoint)
+	var data []map[string]interface{}
+	if _, err := fetchJSON(rc, endpoint, &data); err != nil {
+		return fmt.Errorf("Marketplace API - %w", err)
+	}
+
+	fmt.Fprintf(rc.Out, "✅ Marketplace API dostupné na: %s\n", endpoint)
+	fmt.Fprintf(rc.Out, "   Počet tasků: %d\n", len(data))
+	if len(data) > 0 {
+		if title, ok := data[0]["title"].(string); ok {
+			fmt.Fprintf(rc.Out, "   První task: %s\n", title)
+		}
+	}
+	return nil
+}
+
+func testNotificationCreation(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🔔 TEST 4: Notification Creation")
+	client := rc.Client
+
+	resp, err := client.Post(rc.Config.BackendURL+"/api/notifications/test/create-sample", "application/json", nil)
+	if err != nil {
+		return requestFailure("Notification creation - selhala", err)
 	}
 	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return failf(ReasonBadStatus, "Notification creation - status %d", resp.StatusCode)
+	}
+	body, err := readBody(rc, resp)
+	if err != nil {
+		return requestFailure("Notification creation", err)
+	}
 	var data map[string]interface{}
 	if err := json.Unmarshal(body, &data); err != nil {
-		fmt.Printf("❌ Notification response neobsahuje platný JSON: %v\n", err)
-		return false
+		return failf(ReasonSchema, "Notification response neobsahuje platný JSON: %v", err)
+	}
+
+	id, ok := data["id"]
+	if !ok {
+		return failf(ReasonSchema, "Notification response neobsahuje ID")
 	}
 
-	if id, ok := data["id"]; ok {
-		fmt.Printf("✅ Notification vytvořena s ID: %v\n", id)
-		fmt.Printf("   Response: %s\n", string(body))
+	fmt.Fprintf(rc.Out, "✅ Notification vytvořena s ID: %v\n", id)
+	fmt.Fprintf(rc.Out, "   Response: %s\n", string(body))
 
-		// Počkat a zkusit načíst notifikace
-		fmt.Println("⏳ Čekám 2 sekundy a zkusím načíst notifikace...")
-		time.Sleep(2 * time.Second)
+	// Počkat a zkusit načíst notifikace
+	fmt.Fprintln(rc.Out, "⏳ Čekám 2 sekundy a zkusím načíst notifikace...")
+	time.Sleep(2 * time.Second)
 
-		endpoints := []string{
-			"http://localhost:8000/api/notifications",
-			"http://localhost:8000/notifications",
+	if endpoint, err := rc.endpoint(EndpointNotifications); err == nil {
+		var notifData []interface{}
+		if _, err := fetchJSON(rc, endpoint, &notifData); err == nil {
+			fmt.Fprintf(rc.Out, "✅ Notifikace načteny z: %s\n", endpoint)
+			fmt.Fprintf(rc.Out, "   Počet notifikací: %d\n", len(notifData))
 		}
+	}
+	return nil
+}
 
-		for _, endpoint := range endpoints {
-			notifResp, err := client.Get(endpoint)
-			if err != nil {
-				continue
-			}
-			defer notifResp.Body.Close()
-
-			if notifResp.StatusCode == 200 {
-				notifBody, _ := io.ReadAll(notifResp.Body)
-				var notifData []interface{}
-				if err := json.Unmarshal(notifBody, &notifData); err == nil {
-					fmt.Printf("✅ Notifikace načteny z: %s\n", endpoint)
-					fmt.Printf("   Počet notifikací: %d\n", len(notifData))
-					break
-				}
-			}
+// userNotification is one entry of /api/notifications/me.
+type userNotification struct {
+	Type   string `json:"notification_type"`
+	Title  string `json:"title"`
+	TaskID *int   `json:"related_task_id"`
+}
+
+// hasNotification reports whether the current user has a notification
+// accepted by match.
+func hasNotification(rc *RunContext, match func(userNotification) bool) (bool, error) {
+	var notifications []userNotification
+	if _, err := fetchJSON(rc, rc.Config.BackendURL+"/api/notifications/me", &notifications); err != nil {
+		return false, err
+	}
+	for _, n := range notifications {
+		if match(n) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func testLeaderboardAPI(rc *RunContext) error {
+	fmt.Fprintln(rc.Out, "\n🏆 TEST 5: Leaderboard API")
+
+	endpoint, err := rc.endpoint(EndpointLeaderboard)
+	if err != nil {
+		return fmt.Errorf("Leaderboard API - %w", err)
+	}
+	endpoint = rc.paramURL(endpoint)
+	var data []map[string]interface{}
+	if _, err := fetchJSON(rc, endpoint, &data); err != nil {
+		return fmt.Errorf("Leaderboard API - %w", err)
+	}
+
+	fmt.Fprintf(rc.Out, "✅ Leaderboard API dostupné na: %s\n", endpoint)
+	fmt.Fprintf(rc.Out, "   Počet uživatelů: %d\n", len(data))
+	if len(data) > 0 {
+		name := data[0]["name"]
+		points := data[0]["points"]
+		if name == nil {
+			name = data[0]["username"]
+		}
+		if points == nil {
+			points = data[0]["score"]
+		}
+		fmt.Fprintf(rc.Out, "   Top uživatel: %v s %v body\n", name, points)
+	}
+	return nil
+}
+
+func main() {
+	defer func() {
+		if r := recover(); r != nil {
+			reportHarnessError(TelemetryPanic, fmt.Errorf("%v", r), panicFrames()...)
+			panic(r)
 		}
-		return true
+	}()
+
+	args := os.Args[1:]
+	command := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
 	}
 
-	fmt.Println("❌ Notification response neobsahuje ID")
-	return false
+	switch command {
+	case "run":
+		os.Exit(runCommand(args))
+	case "selftest":
+		os.Exit(selftestCommand(args))
+	case "daemon":
+		os.Exit(daemonCommand(args))
+	case "merge":
+		os.Exit(mergeCommand(args))
+	case "verify":
+		os.Exit(verifyCommand(args))
+	case "init":
+		os.Exit(initCommand(args))
+	default:
+		fmt.Printf("❌ Neznámý příkaz: %s (run, selftest, daemon, merge, verify, init)\n", command)
+		os.Exit(2)
+	}
 }
 
-func testLeaderboardAPI() bool {
-	fmt.Println("\n🏆 TEST 5: Leaderboard API")
-	client := &http.Client{Timeout: 5 * time.Second}
+func parseConfig(name string, args []string) (Config, error) {
+	cfg := Config{}
+	var chaos, failOn, tz, shard, signKey, warehouseFormat string
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&cfg.StateDir, "state-dir", ".able2flow/runs", "adresář se stavem běhů pro -resume")
+	fs.StringVar(&cfg.ResumeID, "resume", "", "dokončí přerušený běh s daným ID (spustí jen nedokončené testy)")
+	fs.StringVar(&cfg.RunID, "run-id", "", "ID nového běhu (výchozí vygenerované); shardy jednoho CI běhu sdílejí stejné, aby je merge spároval")
+	fs.StringVar(&cfg.ConfigPath, "config", "", "JSON soubor s profily prostředí (URL a autentizace)")
+	fs.StringVar(&cfg.Env, "env", "dev", "prostředí z -config souboru")
+	fs.StringVar(&cfg.BackendURL, "backend", "http://localhost:8000", "URL backendu")
+	cfg.Services = map[string]string{}
+	fs.Func("service", "URL samostatné služby backendu jako služba=url (tasks, notifications, users), lze opakovat", func(value string) error {
+		return parseService(cfg.Services, value)
+	})
+	fs.StringVar(&cfg.FrontendURL, "frontend", "http://localhost:5173", "URL frontendu")
+	fs.DurationVar(&cfg.RequestTimeout, "timeout", 5*time.Second, "timeout jednoho HTTP požadavku")
+	fs.StringVar(&cfg.ReportPath, "report", "/Users/lhradek/code/work/flowable/e2e_test_report.txt", "cesta textového reportu")
+	fs.DurationVar(&cfg.ClockSkewWarn, "clock-skew-warn", 2*time.Second, "posun hodin testeru proti Date headeru serveru, nad kterým běh hlásí varování (0 = vypnuto)")
+	fs.DurationVar(&cfg.ClockSkewFail, "clock-skew-fail", 30*time.Second, "posun hodin, nad kterým běh selže (0 = vypnuto)")
+	fs.StringVar(&tz, "tz", "UTC", "časová zóna všech časových údajů ve výstupu a reportech (např. Europe/Prague)")
+	fs.BoolVar(&cfg.Repro, "repro", true, "u selhaných testů uložit vedle reportu curl skript, který zopakuje jejich požadavky")
+	fs.StringVar(&cfg.JSONReportPath, "json-report", "", "cesta JSON reportu s příčinami selhání (pro dashboardy)")
+	fs.StringVar(&signKey, "sign-key", "", "Ed25519 privátní klíč (PKCS #8 PEM); podepíše JSON report do <report>.sig")
+	fs.BoolVar(&cfg.Smoke, "smoke", false, "spustí jen smoke testy s pevným rozpočtem 60 s (post-deploy gate)")
+	fs.StringVar(&cfg.BudgetPath, "budget", "", "YAML soubor s časovými rozpočty suit (smoke: 60s, functional: 5m); překročení shodí běh")
+	fs.StringVar(&cfg.TimingFile, "timing-file", "", "cesta JSON souboru s dobou suite a jednotlivých testů (pro CI)")
+	fs.StringVar(&cfg.WarehouseURL, "warehouse", "", "URL, kam se po běhu odešlou výsledky testů pro analytiku (BigQuery insertAll nebo HTTP endpoint); token v "+warehouseTokenEnv)
+	fs.StringVar(&warehouseFormat, "warehouse-format", WarehouseNDJSON, "formát -warehouse: bigquery nebo ndjson")
+	fs.StringVar(&cfg.CostReportPath, "cost-report", "", "cesta JSON reportu nákladů: čas a CPU po skupinách testů a kandidáti pro smoke vs nightly")
+	fs.DurationVar(&cfg.GateTarget, "gate-target", smokeBudget, "cílová doba deploy gate (smoke testů) pro -cost-report")
+	fs.StringVar(&cfg.DeployID, "deploy-id", "", "ID nasazení, kterým se označí řádky ve warehouse")
+	fs.StringVar(&failOn, "fail-on", string(SeverityMajor), "nejnižší severita selhání, která shodí běh (critical, major, minor)")
+	fs.StringVar(&shard, "shard", "", "spustí jen k-tou z n částí testů (např. 2/5) pro paralelní CI joby; výsledky sloučí příkaz merge")
+	fs.IntVar(&cfg.Parallel, "parallel", 1, "počet paralelně běžících testů")
+	fs.IntVar(&cfg.Retries, "retries", 0, "počet opakování neúspěšného testu")
+	fs.Int64Var(&cfg.MaxBodyBytes, "max-body", 5<<20, "maximální velikost čteného těla odpovědi v bajtech")
+	fs.StringVar(&chaos, "chaos", "", "chaos akce mezi testy, oddělené čárkou (restart-backend, drop-db, startup-order)")
+	fs.StringVar(&cfg.BackendContainer, "backend-container", "able2flow-backend", "docker kontejner backendu pro restart-backend")
+	fs.StringVar(&cfg.ComposeFile, "compose-file", "", "docker compose soubor prostředí pro startup-order")
+	fs.StringVar(&cfg.ComposeBackend, "compose-backend", "backend", "compose služba backendu")
+	fs.StringVar(&cfg.ComposeDB, "compose-db", "db", "compose služba databáze")
+	fs.StringVar(&cfg.ToxiproxyURL, "toxiproxy", "", "URL toxiproxy API (např. http://localhost:8474); zapíná DB fault testy a drop-db")
+	fs.StringVar(&cfg.ToxiproxyProxy, "toxiproxy-proxy", "able2flow-db", "název toxiproxy proxy mezi backendem a DB")
+	fs.StringVar(&cfg.DependencyToggle, "dependency-toggle", "", "nekritická závislost, kterou test degradace vypne přes admin API (email) nebo compose službu (email=mailpit)")
+	fs.DurationVar(&cfg.RecoveryTimeout, "recovery-timeout", 60*time.Second, "maximální doba zotavení po chaos akci")
+	fs.StringVar(&cfg.BackupEndpoint, "backup-endpoint", "", "cesta backup endpointu backendu (POST vrací soubor zálohy)")
+	fs.StringVar(&cfg.BackupCmd, "backup-cmd", "", "shell příkaz zapisující zálohu do $BACKUP_FILE")
+	fs.StringVar(&cfg.RestoreCmd, "restore-cmd", "", "shell příkaz obnovující $BACKUP_FILE do scratch prostředí")
+	fs.StringVar(&cfg.RestoreURL, "restore-url", "http://localhost:8001", "URL backendu ve scratch prostředí")
+	fs.StringVar(&cfg.AssetManifest, "asset-manifest", "", "cesta Vite build manifestu na frontendu (např. /.vite/manifest.json); zapíná kontrolu integrity assetů")
+	fs.StringVar(&cfg.FrontendDist, "frontend-dist", "", "lokální build frontendu (dist) pro porovnání nasazených assetů bajt po bajtu")
+	fs.StringVar(&cfg.SearchPath, "search-path", "/api/tasks/search", "cesta search endpointu (dotaz v parametru q)")
+	fs.StringVar(&cfg.AdminPath, "admin-path", "/api/admin", "prefix admin API (users, tasks/{id}/feature); suite běží, když profil definuje roli admin")
+	fs.DurationVar(&cfg.ExpiryGrace, "expiry-grace", 0, "doba, do které backend zpracuje expiraci tasku po termínu; zapíná test expirace (0 = vypnuto)")
+	fs.DurationVar(&cfg.StaleTokenTTL, "stale-token-ttl", 0, "platnost krátkého tokenu role user; zapíná test vypršení session (0 = vypnuto)")
+	fs.StringVar(&cfg.TestClockPath, "test-clock-path", "", "cesta testovacích hodin backendu (POST advance_seconds); token zestárne bez čekání")
+	fs.StringVar(&cfg.AvatarPath, "avatar-path", "", "cesta upload endpointu avatarů (multipart pole file); zapíná test variant avatarů")
+	fs.StringVar(&cfg.MailCatcherURL, "mailcatcher", "", "URL mail-catcheru (Mailpit nebo MailHog); zapíná kontrolu emailových notifikací")
+	fs.StringVar(&cfg.MailTo, "mail-to", "", "emailová adresa testovacího účtu, na kterou chodí notifikace")
+	fs.StringVar(&cfg.FlagsPath, "flags-path", "/api/feature-flags", "cesta endpointu feature flagů; vypnuté flagy přeskočí závislé testy (prázdná = bez flagů)")
+	fs.StringVar(&cfg.ExportPath, "export-path", "/api/users/me/export", "cesta endpointu pro export uživatelských dat (format=json|csv)")
+	fs.StringVar(&cfg.ImportPath, "import-path", "", "cesta import endpointu pro round-trip do scratch účtu")
+	fs.StringVar(&cfg.CSVImportPath, "csv-import-path", "", "cesta endpointu hromadného CSV importu tasků (multipart pole file); zapíná test importu")
+	fs.StringVar(&cfg.WSPath, "ws-path", "", "cesta WebSocket endpointu notifikací; zapíná test škálování WebSocket spojení")
+	fs.IntVar(&cfg.WSConnections, "ws-connections", 50, "počet souběžných WebSocket spojení")
+	fs.DurationVar(&cfg.WSHold, "ws-hold", 5*time.Second, "jak dlouho držet WebSocket spojení pingy před broadcastem")
+	fs.DurationVar(&cfg.WSLatency, "ws-latency", 2*time.Second, "maximální doba doručení broadcast notifikace na všechna spojení")
+	fs.DurationVar(&cfg.JobDeadline, "job-deadline", 60*time.Second, "maximální doba, za kterou musí asynchronní job (202 s Location) doběhnout")
+	fs.StringVar(&cfg.Journeys, "journeys", "", "journeys z knihovny, např. onboarding,power-user=5 (jméno[=váha])")
+	fs.DurationVar(&cfg.LoadDuration, "load-duration", 0, "spustí load režim s váženými journeys na danou dobu")
+	fs.IntVar(&cfg.LoadWorkers, "load-workers", 10, "počet souběžných workerů v load režimu")
+	fs.Float64Var(&cfg.LoadMaxErrorRate, "load-max-error-rate", 0.01, "maximální podíl chybných journeys v load režimu")
+	fs.StringVar(&cfg.LoadSnapshotPath, "load-snapshots", "", "JSONL soubor s průběžnými snapshoty load běhu (výchozí v -state-dir)")
+	fs.DurationVar(&cfg.LoadSnapshotInterval, "load-snapshot-interval", 30*time.Second, "interval heartbeatu a snapshotu metrik v load režimu")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	cfg.Args = args
 
-	endpoints := []string{
-		"http://localhost:8000/api/leaderboard",
-		"http://localhost:8000/leaderboard",
-		"http://localhost:8000/api/users/leaderboard",
-		"http://localhost:8000/api/users",
+	if chaos != "" {
+		cfg.Chaos = strings.Split(chaos, ",")
+	}
+	if signKey != "" {
+		var err error
+		if cfg.SignKey, err = loadSigningKey(signKey); err != nil {
+			fmt.Fprintf(fs.Output(), "❌ -sign-key: %v\n", err)
+			return cfg, err
+		}
+	}
+	if shard != "" {
+		var err error
+		if cfg.Shard, err = parseShard(shard); err != nil {
+			fmt.Fprintf(fs.Output(), "❌ -shard: %v\n", err)
+			return cfg, err
+		}
+	}
+	severity, err := parseSeverity(failOn)
+	if err != nil {
+		fmt.Fprintf(fs.Output(), "❌ -fail-on: %v\n", err)
+		return cfg, err
+	}
+	cfg.FailOn = severity
+	if cfg.WarehouseFormat, err = parseWarehouseFormat(warehouseFormat); err != nil {
+		fmt.Fprintf(fs.Output(), "❌ -warehouse-format: %v\n", err)
+		return cfg, err
+	}
+	if cfg.Location, err = time.LoadLocation(tz); err != nil {
+		fmt.Fprintf(fs.Output(), "❌ -tz: %v\n", err)
+		return cfg, err
+	}
+	envSet := false
+	fs.Visit(func(f *flag.Flag) { envSet = envSet || f.Name == "env" })
+	if envSet && cfg.ConfigPath == "" {
+		err := fmt.Errorf("-env vyžaduje -config, bez profilu by běh šel proti výchozím URL")
+		fmt.Fprintf(fs.Output(), "❌ %v\n", err)
+		return cfg, err
+	}
+	if cfg.ConfigPath != "" {
+		if err := loadProfile(&cfg, fs, cfg.ConfigPath, cfg.Env); err != nil {
+			fmt.Fprintf(fs.Output(), "❌ -config: %v\n", err)
+			return cfg, err
+		}
 	}
+	if cfg.MailCatcherURL != "" && cfg.MailTo == "" {
+		err := fmt.Errorf("-mailcatcher vyžaduje -mail-to")
+		fmt.Fprintf(fs.Output(), "❌ %v\n", err)
+		return cfg, err
+	}
+	if cfg.StaleTokenTTL > 0 && cfg.Roles[RoleUser].LoginPath == "" {
+		err := fmt.Errorf("-stale-token-ttl vyžaduje v profilu roli %s se session přihlášením (login_path)", RoleUser)
+		fmt.Fprintf(fs.Output(), "❌ %v\n", err)
+		return cfg, err
+	}
+	if cfg.WSPath != "" && cfg.WSConnections < 1 {
+		err := fmt.Errorf("-ws-connections musí být alespoň 1")
+		fmt.Fprintf(fs.Output(), "❌ %v\n", err)
+		return cfg, err
+	}
+	if cfg.LoadDuration > 0 && cfg.LoadSnapshotInterval <= 0 {
+		err := fmt.Errorf("-load-snapshot-interval musí být kladný")
+		fmt.Fprintf(fs.Output(), "❌ %v\n", err)
+		return cfg, err
+	}
+	if cfg.BudgetPath != "" {
+		if cfg.Budgets, err = loadBudgets(cfg.BudgetPath); err != nil {
+			fmt.Fprintf(fs.Output(), "❌ -budget: %v\n", err)
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
 
-	for _, endpoint := range endpoints {
-		resp, err := client.Get(endpoint)
+func newRunContext(cfg Config) *RunContext {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.LoadDuration > 0 {
+		transport.MaxIdleConnsPerHost = cfg.LoadWorkers
+	}
+	timing := newTimingMetrics()
+	login := &http.Client{Timeout: cfg.RequestTimeout, Transport: transport}
+	// The scratch backend restored by the backup test takes the same
+	// credentials, but is not a service of the run otherwise.
+	authBackends := cfg.backendURLs()
+	if cfg.RestoreCmd != "" {
+		authBackends = append(authBackends, cfg.RestoreURL)
+	}
+	client := func(auth AuthProvider) *http.Client {
+		return &http.Client{
+			Timeout: cfg.RequestTimeout,
+			Transport: &timingTransport{
+				next:     &authTransport{next: transport, auth: auth, backends: authBackends},
+				metrics:  timing,
+				backends: cfg.backendURLs(),
+			},
+		}
+	}
+	auth := newAuthProvider(cfg, cfg.Auth, login)
+	rc := &RunContext{
+		Config: cfg,
+		Client: client(auth),
+		Auth:   auth,
+		Roles:  map[string]*http.Client{},
+		Timing: timing,
+		Out:    os.Stdout,
+	}
+	for role, roleAuth := range cfg.Roles {
+		rc.Roles[role] = client(newAuthProvider(cfg, roleAuth, login))
+	}
+	if cfg.ToxiproxyURL != "" {
+		// A plain client: the proxy admin API must get neither app
+		// credentials nor entries in the Server-Timing scorecard.
+		rc.Toxiproxy = NewToxiproxyClient(cfg.ToxiproxyURL, &http.Client{Timeout: cfg.RequestTimeout})
+	}
+	return rc
+}
+
+func runCommand(args []string) int {
+	cfg, err := parseConfig("run", args)
+	if err != nil {
+		reportHarnessError(TelemetryConfig, err)
+		return 2
+	}
+	if cfg.ResumeID != "" {
+		if cfg, err = resumeConfig(cfg); err != nil {
+			reportHarnessError(TelemetryConfig, err)
+			fmt.Printf("❌ %v\n", err)
+			return 2
+		}
+	}
+	if cfg.Smoke && (len(cfg.Chaos) > 0 || cfg.LoadDuration > 0) {
+		fmt.Println("❌ -smoke nelze kombinovat s -chaos ani -load-duration")
+		return 2
+	}
+
+	if cfg.LoadDuration > 0 {
+		journeys, err := selectJourneys(cfg.Journeys)
 		if err != nil {
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == 200 {
-			body, _ := io.ReadAll(resp.Body)
-			var data []map[string]interface{}
-			if err := json.Unmarshal(body, &data); err == nil {
-				fmt.Printf("✅ Leaderboard API dostupné na: %s\n", endpoint)
-				fmt.Printf("   Počet uživatelů: %d\n", len(data))
-				if len(data) > 0 {
-					name := data[0]["name"]
-					points := data[0]["points"]
-					if name == nil {
-						name = data[0]["username"]
-					}
-					if points == nil {
-						points = data[0]["score"]
-					}
-					fmt.Printf("   Top uživatel: %v s %v body\n", name, points)
-				}
-				return true
+			fmt.Printf("❌ %v\n", err)
+			return 2
+		}
+
+		rc := newRunContext(cfg)
+		var runnable []Journey
+		for _, j := range journeys {
+			if hasRole(rc, j) {
+				runnable = append(runnable, j)
+			} else {
+				fmt.Printf("⚠️ Journey %s vynechána, profil nemá roli %s\n", j.Name, j.Role)
 			}
 		}
+		if len(runnable) == 0 {
+			fmt.Println("❌ Žádná journey nemůže běžet")
+			return 2
+		}
+		journeys = runnable
+		fmt.Printf("🔥 LOAD TEST: %d workerů po dobu %s\n", cfg.LoadWorkers, cfg.LoadDuration)
+		stats, timeline := runLoad(rc, journeys)
+		if !printLoadSummary(rc, journeys, stats, timeline) {
+			return 1
+		}
+		return 0
 	}
 
-	fmt.Println("❌ Leaderboard API - žádný endpoint nenalezen")
-	return false
+	results, err := executeRun(cfg)
+	if err != nil {
+		reportHarnessError(TelemetryConfig, err)
+		fmt.Printf("❌ %v\n", err)
+		return 2
+	}
+	if len(blockingFailures(results, cfg.FailOn)) > 0 {
+		return 1
+	}
+	return 0
 }
 
-func main() {
+func buildTests(rc *RunContext) ([]testCase, error) {
+	cfg := rc.Config
+	tests := []testCase{
+		{"Backend Health", testBackendHealth, true, SeverityCritical, "", SuiteCore},
+		{"Frontend Availability", testFrontendAvailability, true, SeverityCritical, "", SuiteCore},
+		{"Frontend API Base", testFrontendAPIBase, true, SeverityMajor, "", SuiteCore},
+		{"Crawler Files", testCrawlerFiles, true, SeverityMinor, "", SuiteCore},
+		{"Response Compression", testCompression, false, SeverityMinor, ServiceTasks, SuiteCore},
+		{"Marketplace API", testMarketplaceAPI, true, SeverityMajor, ServiceTasks, SuiteCore},
+		{"Notification Creation", testNotificationCreation, false, SeverityMinor, ServiceNotifications, SuiteCore},
+		{"Leaderboard API", testLeaderboardAPI, true, SeverityMinor, ServiceUsers, SuiteCore},
+		{"Search Relevance", testSearchRelevance, false, SeverityMajor, ServiceTasks, SuiteCore},
+		{"ETag Invalidation", testETagInvalidation, false, SeverityMinor, ServiceTasks, SuiteCore},
+		{"User Data Export", testUserDataExport, false, SeverityMajor, ServiceUsers, SuiteCore},
+		{"Localization", testLocalization, false, SeverityMinor, ServiceNotifications, SuiteCore},
+		{"Audit Log", testAuditLog, false, SeverityMajor, ServiceTasks, SuiteCore},
+		{"Error Envelope", testErrorEnvelope, false, SeverityMajor, "", SuiteCore},
+		{"Content Negotiation", testContentNegotiation, false, SeverityMajor, "", SuiteCore},
+		{"Ordering Under Writes", testOrderingUnderWrites, false, SeverityMajor, ServiceTasks, SuiteCore},
+		{"Reward Redemption", testRewardRedemption, false, SeverityMajor, "", SuiteCore},
+		{"Locale Formatting", testLocaleFormatting, false, SeverityMinor, "", SuiteCore},
+	}
+	if cfg.Journeys != "" {
+		journeys, err := selectJourneys(cfg.Journeys)
+		if err != nil {
+			return nil, err
+		}
+		for _, j := range journeys {
+			tests = append(tests, journeyTest(j))
+		}
+	}
+	if rc.Toxiproxy != nil {
+		tests = append(tests,
+			testCase{"DB Latency Degradation", testDBLatency, false, SeverityMajor, "", SuiteDBFaults},
+			testCase{"DB Bandwidth Limit", testDBBandwidth, false, SeverityMinor, "", SuiteDBFaults},
+			testCase{"DB Connection Reset", testDBConnectionReset, false, SeverityMajor, "", SuiteDBFaults},
+		)
+	}
+
+	if cfg.ExpiryGrace > 0 {
+		tests = append(tests, testCase{"Task Expiry", testTaskExpiry, false, SeverityMajor, ServiceTasks, SuiteOptIn})
+	}
+	if cfg.StaleTokenTTL > 0 {
+		tests = append(tests, testCase{"Session Expiry", testSessionExpiry, false, SeverityMajor, ServiceUsers, SuiteOptIn})
+	}
+	if cfg.CSVImportPath != "" {
+		tests = append(tests, testCase{"CSV Task Import", testCSVImport, false, SeverityMajor, ServiceTasks, SuiteOptIn})
+	}
+	if cfg.DependencyToggle != "" {
+		tests = append(tests, testCase{"Dependency Degradation", testDependencyDegradation, false, SeverityMajor, "", SuiteOptIn})
+	}
+	if cfg.WSPath != "" {
+		tests = append(tests, testCase{"WebSocket Scale", testWebSocketScale, false, SeverityMajor, ServiceNotifications, SuiteOptIn})
+	}
+	if cfg.MailCatcherURL != "" {
+		tests = append(tests, testCase{"Email Notifications", testEmailNotifications, false, SeverityMinor, ServiceNotifications, SuiteOptIn})
+	}
+	if cfg.AvatarPath != "" {
+		tests = append(tests, testCase{"Avatar Upload", testAvatarUpload, false, SeverityMinor, ServiceUsers, SuiteOptIn})
+	}
+	if _, ok := cfg.Roles[RoleAdmin]; ok {
+		tests = append(tests,
+			testCase{"Admin Users", testAdminUsers, false, SeverityMajor, ServiceUsers, SuiteAdmin},
+			testCase{"Admin Feature Task", testAdminFeatureTask, false, SeverityMinor, ServiceTasks, SuiteAdmin},
+		)
+		if cfg.Roles[RoleUser].Type == "session" {
+			tests = append(tests, testCase{"Admin Suspend User", testAdminSuspendUser, false, SeverityCritical, ServiceUsers, SuiteAdmin})
+		}
+	}
+	if cfg.RestoreCmd != "" && (cfg.BackupEndpoint != "" || cfg.BackupCmd != "") {
+		tests = append(tests, testCase{"Backup & Restore", testBackupRestore, false, SeverityCritical, "", SuiteOptIn})
+	}
+	if cfg.AssetManifest != "" || cfg.FrontendDist != "" {
+		tests = append(tests, testCase{"Static Asset Integrity", testAssetIntegrity, false, SeverityMajor, "", SuiteOptIn})
+	}
+	tests = expandMatrix(tests, cfg.Matrix)
+	if cfg.Smoke {
+		var smoke []testCase
+		for _, test := range tests {
+			if test.smoke {
+				smoke = append(smoke, test)
+			}
+		}
+		tests = smoke
+	}
+	return tests, nil
+}
+
+// executeRun runs the functional suite described by cfg, prints the final
+// report and saves it to cfg.ReportPath.
+func executeRun(cfg Config) (TestResult, error) {
+	actions, err := chaosActions(cfg.Chaos)
+	if err != nil {
+		return TestResult{}, err
+	}
+	rc := newRunContext(cfg)
+	tests, err := buildTests(rc)
+	if err != nil {
+		return TestResult{}, err
+	}
+	tests = shardTests(tests, cfg.Shard)
+	state, err := openRunState(cfg)
+	if err != nil {
+		return TestResult{}, err
+	}
+	if cfg.Smoke {
+		cancel := startSmokeBudget(rc)
+		defer cancel()
+	}
+
 	fmt.Println("============================================================")
 	fmt.Println("🚀 E2E TEST ANT HILL APLIKACE")
-	fmt.Printf("⏰ Čas: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	if cfg.Smoke {
+		fmt.Printf("💨 Smoke režim: rozpočet %s, testů: %d\n", smokeBudget, len(tests))
+	}
+	if cfg.Shard.Total > 0 {
+		fmt.Printf("🧩 Shard %s: testů %d\n", cfg.Shard, len(tests))
+	}
+	started := time.Now()
+	fmt.Printf("⏰ Čas: %s\n", timestamp(cfg, started))
+	if cfg.ResumeID != "" {
+		fmt.Printf("🔄 Pokračuji v běhu %s (dokončených položek: %d)\n", state.RunID, len(state.prior))
+	} else {
+		fmt.Printf("🆔 Běh: %s (po přerušení: run -resume %s)\n", state.RunID, state.RunID)
+	}
+	if cfg.ConfigPath != "" {
+		fmt.Printf("🔐 Prostředí: %s (%s), auth: %s\n", cfg.Env, cfg.ConfigPath, rc.Auth.Name())
+	}
+	if len(cfg.Services) > 0 {
+		fmt.Printf("🧱 Služby: %s\n", formatServices(cfg.Services))
+	}
 	fmt.Println("============================================================")
 
-	results := TestResult{
-		Passed: []string{},
-		Failed: []string{},
+	skew, skewed := checkClockSkew(rc)
+	if skewed {
+		icon := "❌"
+		if skew.Passed {
+			icon = "⚠️"
+		}
+		fmt.Printf("%s %s\n", icon, skew.Message)
 	}
+	rc.Endpoints = resolveEndpoints(rc)
+	flags := loadFeatureFlags(rc, state)
+	results := runSuite(rc, gateTests(tests, flags), actions, state)
+	results.FeatureFlags = flags
+	if skewed {
+		results.Outcomes = append(results.Outcomes, skew)
+		if skew.Passed {
+			results.Passed = append(results.Passed, skew.Name)
+			results.Warnings = append(results.Warnings, skew.Name)
+		} else {
+			results.Failed = append(results.Failed, skew.Name)
+		}
+	}
+	elapsed := time.Since(started)
+	if o, over := checkBudget(cfg, elapsed); over {
+		fmt.Printf("❌ %s\n", o.Message)
+		results.Failed = append(results.Failed, o.Name)
+		results.Outcomes = append(results.Outcomes, o)
+	}
+	results.Timing = rc.Timing.summary()
+	results.Scorecard = rc.Timing.scorecard()
+	// A resumed run started when it was first launched.
+	results.Started = state.Started
+	results.RunID = state.RunID
+	printReport(cfg, results)
+	saveReport(cfg, results)
+	if cfg.JSONReportPath != "" {
+		saveJSONReport(cfg, results)
+	}
+	if cfg.TimingFile != "" {
+		saveTimingFile(cfg, results, elapsed)
+	}
+	if cfg.CostReportPath != "" {
+		saveCostReport(cfg, tests, results)
+	}
+	if cfg.WarehouseURL != "" {
+		saveWarehouse(cfg, results)
+	}
+	return results, nil
+}
 
-	tests := []struct {
-		name string
-		fn   func() bool
-	}{
-		{"Backend Health", testBackendHealth},
-		{"Frontend Availability", testFrontendAvailability},
-		{"Marketplace API", testMarketplaceAPI},
-		{"Notification Creation", testNotificationCreation},
-		{"Leaderboard API", testLeaderboardAPI},
+// timestamp formats t in the -tz zone. Console output, text and JSON reports
+// all use this one format.
+func timestamp(cfg Config, t time.Time) string {
+	if cfg.Location != nil {
+		t = t.In(cfg.Location)
 	}
+	return t.Format(time.RFC3339)
+}
 
-	for _, test := range tests {
-		if test.fn() {
-			results.Passed = append(results.Passed, test.name)
-		} else {
-			results.Failed = append(results.Failed, test.name)
-		}
+func successRate(results TestResult) (int, int) {
+	total := len(results.Passed) + len(results.Failed)
+	rate := 0
+	if total > 0 {
+		rate = (100 * len(results.Passed)) / total
 	}
+	return total, rate
+}
+
+func printReport(cfg Config, results TestResult) {
+	total, rate := successRate(results)
 
 	// Final report
 	fmt.Println("\n============================================================")
 	fmt.Println("📊 E2E TEST REPORT - ANT HILL")
 	fmt.Println("============================================================")
 
-	fmt.Printf("\n✅ CO FUNGUJE (%d/%d):\n", len(results.Passed), len(tests))
+	fmt.Printf("\n✅ CO FUNGUJE (%d/%d):\n", len(results.Passed), total)
 	for _, item := range results.Passed {
 		fmt.Printf("  ✅ %s\n", item)
 	}
 
-	fmt.Printf("\n❌ CO NEFUNGUJE (%d/%d):\n", len(results.Failed), len(tests))
+	fmt.Printf("\n❌ CO NEFUNGUJE (%d/%d):\n", len(results.Failed), total)
 	if len(results.Failed) == 0 {
 		fmt.Println("  Vše funguje perfektně! 🎉")
 	} else {
-		for _, item := range results.Failed {
-			fmt.Printf("  ❌ %s\n", item)
+		for _, o := range failedOutcomes(results) {
+			fmt.Printf("  %s %s (%s) [%s] %s\n", failureIcon(cfg, o), o.Name, o.Severity, o.Reason, o.Message)
+		}
+		fmt.Println("\n🧩 PŘÍČINY SELHÁNÍ:")
+		for _, c := range reasonCounts(results.Outcomes) {
+			fmt.Printf("  %-16s %d\n", c.Reason, c.Count)
 		}
 	}
 
-	successRate := 0
-	if len(tests) > 0 {
-		successRate = (100 * len(results.Passed)) / len(tests)
+	if len(results.Flaky) > 0 {
+		fmt.Printf("\n🔁 NESTABILNÍ - prošlo až po opakování (%d):\n", len(results.Flaky))
+		for _, item := range results.Flaky {
+			fmt.Printf("  🔁 %s\n", item)
+		}
 	}
+	fmt.Print(renderWarnings(results))
+	fmt.Print(renderSkipped(results))
+
+	fmt.Print(renderTiming(results.Timing))
+	fmt.Print(renderScorecard(results.Scorecard))
 
 	fmt.Println("\n============================================================")
-	fmt.Printf("📈 Úspěšnost: %d/%d (%d%%)\n", len(results.Passed), len(tests), successRate)
+	fmt.Printf("📈 Úspěšnost: %d/%d (%d%%)\n", len(results.Passed), total, rate)
+	fmt.Println(verdict(cfg, results))
 	fmt.Println("============================================================")
+}
+
+func renderReport(cfg Config, results TestResult) string {
+	total, rate := successRate(results)
 
-	// Save report
 	report := fmt.Sprintf(`
 E2E TEST REPORT - ANT HILL
+Started: %s
 Generated: %s
 
 ✅ CO FUNGUJE (%d/%d):
-`, time.Now().Format(time.RFC3339), len(results.Passed), len(tests))
+`, timestamp(cfg, results.Started), timestamp(cfg, time.Now()), len(results.Passed), total)
 
 	for _, item := range results.Passed {
 		report += fmt.Sprintf("  ✅ %s\n", item)
 	}
 
-	report += fmt.Sprintf("\n❌ CO NEFUNGUJE (%d/%d):\n", len(results.Failed), len(tests))
+	report += fmt.Sprintf("\n❌ CO NEFUNGUJE (%d/%d):\n", len(results.Failed), total)
 	if len(results.Failed) == 0 {
 		report += "  Vše funguje perfektně! 🎉\n"
 	} else {
-		for _, item := range results.Failed {
-			report += fmt.Sprintf("  ❌ %s\n", item)
+		for _, o := range failedOutcomes(results) {
+			report += fmt.Sprintf("  %s %s (%s) [%s] %s\n", failureIcon(cfg, o), o.Name, o.Severity, o.Reason, o.Message)
+			if o.Repro != "" {
+				report += fmt.Sprintf("     🧪 %s\n", o.Repro)
+			}
+		}
+		report += "\n🧩 PŘÍČINY SELHÁNÍ:\n"
+		for _, c := range reasonCounts(results.Outcomes) {
+			report += fmt.Sprintf("  %-16s %d\n", c.Reason, c.Count)
 		}
 	}
 
-	report += fmt.Sprintf("\n📈 Úspěšnost: %d/%d (%d%%)\n", len(results.Passed), len(tests), successRate)
+	if len(results.Flaky) > 0 {
+		report += fmt.Sprintf("\n🔁 NESTABILNÍ - prošlo až po opakování (%d):\n", len(results.Flaky))
+		for _, item := range results.Flaky {
+			report += fmt.Sprintf("  🔁 %s\n", item)
+		}
+	}
+	report += renderWarnings(results)
+	report += renderSkipped(results)
+
+	report += renderTiming(results.Timing)
+	report += renderScorecard(results.Scorecard)
+	report += fmt.Sprintf("\n📈 Úspěšnost: %d/%d (%d%%)\n", len(results.Passed), total, rate)
+	report += verdict(cfg, results) + "\n"
 	report += "\nPOZNÁMKY:\n"
 	report += "- Test proběhl bez browser automation (pouze API testy)\n"
 	report += "- Pro kompletní E2E test včetně UI je potřeba Playwright/Puppeteer\n"
-	report += "- Všechny testy používají localhost:8000 (backend) a localhost:5173 (frontend)\n"
+	report += fmt.Sprintf("- Testy používají %s (backend) a %s (frontend)\n", cfg.BackendURL, cfg.FrontendURL)
+	if len(cfg.Services) > 0 {
+		report += fmt.Sprintf("- Samostatné služby: %s\n", formatServices(cfg.Services))
+	}
+	if cfg.Smoke {
+		report += fmt.Sprintf("- Smoke režim: jen smoke testy s rozpočtem %s\n", smokeBudget)
+	}
+	if cfg.Shard.Total > 0 {
+		report += fmt.Sprintf("- Shard %s: jen část testů, celek sloučí příkaz merge\n", cfg.Shard)
+	}
+	if len(cfg.Chaos) > 0 {
+		report += fmt.Sprintf("- Chaos akce mezi testy: %s\n", strings.Join(cfg.Chaos, ", "))
+	}
+	return report
+}
 
-	reportPath := "/Users/lhradek/code/work/flowable/e2e_test_report.txt"
-	if err := os.WriteFile(reportPath, []byte(report), 0644); err != nil {
+func saveReport(cfg Config, results TestResult) {
+	if err := os.WriteFile(cfg.ReportPath, []byte(renderReport(cfg, results)), 0644); err != nil {
+		reportHarnessError(TelemetryReporter, err)
 		fmt.Printf("\n⚠️ Chyba při ukládání reportu: %v\n", err)
 	} else {
-		fmt.Printf("\n📄 Report uložen do: %s\n", reportPath)
+		fmt.Printf("\n📄 Report uložen do: %s\n", cfg.ReportPath)
+	}
+}
+
+// failureIcon marks failures that do not fail the run as warnings.
+func failureIcon(cfg Config, o TestOutcome) string {
+	if o.Severity.blocks(cfg.FailOn) {
+		return "❌"
+	}
+	return "⚠️"
+}
+
+func verdict(cfg Config, results TestResult) string {
+	blocking := len(blockingFailures(results, cfg.FailOn))
+	warnings := len(results.Failed) - blocking + len(results.Warnings)
+	if blocking > 0 {
+		return fmt.Sprintf("🚦 Běh SELHAL: %d blokujících selhání, %d varování (-fail-on %s)", blocking, warnings, cfg.FailOn)
+	}
+	return fmt.Sprintf("🚦 Běh OK: %d varování (-fail-on %s)", warnings, cfg.FailOn)
+}
+
+func failedOutcomes(results TestResult) []TestOutcome {
+	var failed []TestOutcome
+	for _, o := range results.Outcomes {
+		if !o.Passed && !o.Skipped {
+			failed = append(failed, o)
+		}
+	}
+	return failed
+}
+
+type jsonReportTest struct {
+	Name       string        `json:"name"`
+	Suite      string        `json:"suite,omitempty"`
+	Status     string        `json:"status"`
+	Severity   Severity      `json:"severity"`
+	Reason     FailureReason `json:"reason,omitempty"`
+	Message    string        `json:"message,omitempty"`
+	Attempts   int           `json:"attempts"`
+	DurationMs int64         `json:"duration_ms"`
+	Repro      string        `json:"repro,omitempty"`
+}
+
+// reportTest converts an outcome to its JSON report entry.
+func reportTest(o TestOutcome) jsonReportTest {
+	test := jsonReportTest{
+		Name:       o.Name,
+		Suite:      o.Suite,
+		Status:     "passed",
+		Severity:   o.Severity,
+		Attempts:   o.Attempts,
+		DurationMs: o.Duration.Milliseconds(),
 	}
+	switch {
+	case o.Skipped:
+		test.Status = "skipped"
+		test.Message = o.Message
+	case o.Warning:
+		test.Status = "warning"
+		test.Message = o.Message
+	case !o.Passed:
+		test.Status = "failed"
+		test.Reason = o.Reason
+		test.Message = o.Message
+		test.Repro = o.Repro
+	}
+	return test
+}
+
+type jsonReport struct {
+	Started   string `json:"started"`
+	Generated string `json:"generated"`
+	Backend   string `json:"backend"`
+	Frontend  string `json:"frontend"`
+	RunID     string `json:"run_id,omitempty"`
+	Shard     string `json:"shard,omitempty"`
+	Summary   struct {
+		Total    int                   `json:"total"`
+		Passed   int                   `json:"passed"`
+		Failed   int                   `json:"failed"`
+		Skipped  int                   `json:"skipped"`
+		Warnings int                   `json:"warnings"`
+		Blocking int                   `json:"blocking"`
+		FailOn   Severity              `json:"fail_on"`
+		ByReason map[FailureReason]int `json:"by_reason"`
+	} `json:"summary"`
+	FeatureFlags map[string]bool     `json:"feature_flags,omitempty"`
+	ServerTiming jsonServerTiming    `json:"server_timing"`
+	Scorecard    []jsonEndpointScore `json:"scorecard"`
+	Tests        []jsonReportTest    `json:"tests"`
+}
+
+type jsonServerTiming struct {
+	Requests       int               `json:"requests"`
+	WithHeader     int               `json:"with_header"`
+	AvgRoundTripMs float64           `json:"avg_round_trip_ms"`
+	AvgServerMs    float64           `json:"avg_server_ms"`
+	AvgNetworkMs   float64           `json:"avg_network_ms"`
+	Phases         []jsonPhaseTiming `json:"phases"`
+}
+
+type jsonEndpointScore struct {
+	Endpoint     string  `json:"endpoint"`
+	Calls        int     `json:"calls"`
+	Errors       int     `json:"errors"`
+	ClientErrors int     `json:"client_errors"`
+	P95Ms        float64 `json:"p95_ms"`
+}
+
+type jsonPhaseTiming struct {
+	Name  string  `json:"name"`
+	Count int     `json:"count"`
+	AvgMs float64 `json:"avg_ms"`
+	P95Ms float64 `json:"p95_ms"`
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
 
-	if len(results.Failed) > 0 {
-		os.Exit(1)
+func renderJSONReport(cfg Config, results TestResult) ([]byte, error) {
+	report := jsonReport{
+		Started:      timestamp(cfg, results.Started),
+		Generated:    timestamp(cfg, time.Now()),
+		Backend:      cfg.BackendURL,
+		Frontend:     cfg.FrontendURL,
+		RunID:        results.RunID,
+		Tests:        []jsonReportTest{},
+		FeatureFlags: results.FeatureFlags,
+	}
+	if cfg.Shard.Total > 0 {
+		report.Shard = cfg.Shard.String()
+	}
+	report.Summary.Total, _ = successRate(results)
+	report.Summary.Passed = len(results.Passed)
+	report.Summary.Failed = len(results.Failed)
+	report.Summary.Skipped = len(results.Skipped)
+	report.Summary.Warnings = len(results.Warnings)
+	report.Summary.Blocking = len(blockingFailures(results, cfg.FailOn))
+	report.Summary.FailOn = cfg.FailOn
+	report.Summary.ByReason = map[FailureReason]int{}
+	for _, c := range reasonCounts(results.Outcomes) {
+		report.Summary.ByReason[c.Reason] = c.Count
+	}
+
+	for _, o := range results.Outcomes {
+		report.Tests = append(report.Tests, reportTest(o))
+	}
+
+	t := results.Timing
+	report.ServerTiming = jsonServerTiming{
+		Requests:       t.Requests,
+		WithHeader:     t.WithHeader,
+		AvgRoundTripMs: millis(t.AvgRoundTrip),
+		AvgServerMs:    millis(t.AvgServer),
+		AvgNetworkMs:   millis(t.AvgNetwork),
+	}
+	for _, p := range t.Phases {
+		report.ServerTiming.Phases = append(report.ServerTiming.Phases, jsonPhaseTiming{p.Name, p.Count, millis(p.Avg), millis(p.P95)})
+	}
+	report.Scorecard = []jsonEndpointScore{}
+	for _, e := range results.Scorecard {
+		report.Scorecard = append(report.Scorecard, jsonEndpointScore{e.Endpoint, e.Calls, e.Errors, e.ClientErrors, millis(e.P95)})
+	}
+	return json.MarshalIndent(report, "", "  ")
+}
+
+func saveJSONReport(cfg Config, results TestResult) {
+	data, err := renderJSONReport(cfg, results)
+	if err == nil {
+		err = os.WriteFile(cfg.JSONReportPath, data, 0644)
+	}
+	if err == nil && cfg.SignKey != nil {
+		err = signArtifact(cfg.SignKey, cfg.JSONReportPath, data)
+	}
+	if err != nil {
+		reportHarnessError(TelemetryReporter, err)
+		fmt.Printf("⚠️ Chyba při ukládání JSON reportu: %v\n", err)
+	} else {
+		fmt.Printf("📄 JSON report uložen do: %s\n", cfg.JSONReportPath)
 	}
 }